@@ -10,9 +10,69 @@ const (
 	// defaultSetting — default tag name
 	defaultSetting = "default"
 
-	// duration — type time.Duration
-	duration = "time.Duration"
+	// layout — the time layout tag name
+	layout = "layout"
+
+	// unit — the tag name of the unit of an integer value
+	unit = "unit"
+
+	// bytesUnit — the unit of the byte sizes such as 64MiB
+	bytesUnit = "bytes"
+
+	// base — the tag name of the integer base
+	base = "base"
+
+	// defaultBase — the integer base used by default
+	defaultBase = 10
 
 	// required — the string that indicates that the field is required
 	required = "required"
+
+	// usage — the flag help text tag name
+	usage = "usage"
+
+	// desc — the alternative flag help text tag name
+	desc = "desc"
+
+	// secret — the tag name that marks the values to be redacted in errors
+	secret = "secret"
+
+	// redacted — the replacement of the secret values
+	redacted = "***"
+
+	// sep — the slice separator tag name
+	sep = "sep"
+
+	// separator — the alternative slice separator tag name
+	separator = "separator"
+
+	// defaultSeparator — the slice separator used by default
+	defaultSeparator = ","
+
+	// trim — the tag name that enables trimming of the slice elements
+	trim = "trim"
+
+	// skipEmpty — the tag name that enables dropping of the empty slice elements
+	skipEmpty = "skipempty"
+
+	// kvsep — the map key-value separator tag name
+	kvsep = "kvsep"
+
+	// defaultKeyValueSeparator — the map key-value separator used by default
+	defaultKeyValueSeparator = ":"
+
+	// prefix — the tag name of the env name prefix of a nested struct
+	prefix = "prefix"
+
+	// envPrefix — the alternative tag name of the env name prefix of a nested struct
+	envPrefix = "envPrefix"
+
+	// envNameSeparator — separates the fallback names in the env tag
+	envNameSeparator = ","
+
+	// keySeparator — the word separator of the env names, e.g. DB_MAX_CONNS or UPSTREAM_0_HOST
+	keySeparator = "_"
+
+	// deprecated — the tag name that marks the fallback env names as deprecated
+	deprecated = "deprecated"
 )
@@ -0,0 +1,44 @@
+package settings
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// Decoder — a type that decodes itself from a setting value. The fields which type or
+// pointer to it implements Decoder are decoded by it instead of the built-in conversion.
+// Decoder takes precedence over encoding.TextUnmarshaler that is honored as well.
+type Decoder interface {
+	DecodeSetting(value string) error
+}
+
+var (
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isDecodable returns true if the type or the pointer to it decodes itself.
+func isDecodable(valueType reflect.Type) bool {
+	return implementsDecoding(valueType) || implementsDecoding(reflect.PointerTo(valueType))
+}
+
+func implementsDecoding(valueType reflect.Type) bool {
+	return valueType.Implements(decoderType) || valueType.Implements(textUnmarshalerType)
+}
+
+// decode decodes the raw value with Decoder or encoding.TextUnmarshaler of the value,
+// decoded is false if the value type implements none of them.
+func decode(value reflect.Value, raw string) (decoded bool, err error) {
+	if !value.CanAddr() || !implementsDecoding(value.Addr().Type()) {
+		return false, nil
+	}
+
+	switch decoder := value.Addr().Interface().(type) {
+	case Decoder:
+		return true, decoder.DecodeSetting(raw)
+	case encoding.TextUnmarshaler:
+		return true, decoder.UnmarshalText([]byte(raw))
+	}
+
+	return false, nil
+}
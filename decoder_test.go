@@ -0,0 +1,108 @@
+package settings
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// upperString is decoded by both Decoder and encoding.TextUnmarshaler, Decoder must win.
+type upperString string
+
+func (value *upperString) DecodeSetting(raw string) error {
+	if raw == "" {
+		return errors.New("empty value")
+	}
+	*value = upperString(strings.ToUpper(raw))
+	return nil
+}
+
+func (value *upperString) UnmarshalText(text []byte) error {
+	*value = upperString(text)
+	return nil
+}
+
+type decoderConfig struct {
+	IP      net.IP                `env:"IP"`
+	Addr    netip.Addr            `env:"ADDR"`
+	Level   slog.Level            `env:"LEVEL"`
+	Big     *big.Int              `env:"BIG"`
+	Unset   *big.Int              `env:"UNSET"`
+	Upper   upperString           `env:"UPPER"`
+	Addrs   []netip.Addr          `env:"ADDRS"`
+	Levels  map[string]slog.Level `env:"LEVELS"`
+	Uppers  []upperString         `env:"UPPERS"`
+	Default slog.Level            `default:"warn" env:"DEFAULT"`
+}
+
+func TestLoadDecoders(t *testing.T) {
+	source := MapSource{
+		"IP":     "10.0.0.1",
+		"ADDR":   "::1",
+		"LEVEL":  "debug",
+		"BIG":    "123456789012345678901234567890",
+		"UPPER":  "value",
+		"ADDRS":  "127.0.0.1,192.168.0.1",
+		"LEVELS": "http:info,db:error",
+		"UPPERS": "a,b",
+	}
+
+	var got decoderConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	wantBig, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	switch {
+	case !got.IP.Equal(net.IPv4(10, 0, 0, 1)):
+		t.Errorf("IP = %v", got.IP)
+	case got.Addr != netip.IPv6Loopback():
+		t.Errorf("Addr = %v", got.Addr)
+	case got.Level != slog.LevelDebug || got.Default != slog.LevelWarn:
+		t.Errorf("Level = %v, Default = %v", got.Level, got.Default)
+	case got.Big == nil || got.Big.Cmp(wantBig) != 0:
+		t.Errorf("Big = %v", got.Big)
+	case got.Unset != nil:
+		t.Errorf("Unset = %v, want nil", got.Unset)
+	case got.Upper != "VALUE":
+		t.Errorf("Upper = %v, Decoder must take precedence", got.Upper)
+	case len(got.Addrs) != 2 || got.Addrs[1] != netip.MustParseAddr("192.168.0.1"):
+		t.Errorf("Addrs = %v", got.Addrs)
+	case len(got.Levels) != 2 || got.Levels["db"] != slog.LevelError:
+		t.Errorf("Levels = %v", got.Levels)
+	case len(got.Uppers) != 2 || got.Uppers[0] != "A":
+		t.Errorf("Uppers = %v", got.Uppers)
+	}
+}
+
+func TestLoadDecodersErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		env    string
+		path   string
+	}{
+		{name: "text unmarshaler", source: MapSource{"ADDR": "300.0.0.1"}, env: "ADDR", path: "decoderConfig.Addr"},
+		{name: "decoder", source: MapSource{"UPPER": ""}, env: "UPPER", path: "decoderConfig.Upper"},
+		{name: "element", source: MapSource{"LEVELS": "db:loud"}, env: "LEVELS", path: "decoderConfig.Levels[db]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&decoderConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Env != tt.env || target.Path != tt.path || target.Err == nil {
+				t.Errorf("IncorrectFieldValueError = %+v, want env %s and path %s", target, tt.env, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package settings
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// exportPrefix — the optional keyword in front of a dotenv key
+const exportPrefix = "export"
+
+// Dotenv reads the dotenv file and returns it as a source. The path is used as the
+// layer name in the Provenance.
+func Dotenv(path string) (Source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values, err := ParseDotenv(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return Named(path, values), nil
+}
+
+// ParseDotenv parses dotenv formatted data. Supported syntax:
+//
+//	# a comment
+//	KEY=value            # an inline comment
+//	export KEY=value
+//	KEY='single quoted, taken literally'
+//	KEY="double quoted with \n, \t, \" and \\ escapes
+//	may span several lines"
+//
+// The values are not expanded.
+func ParseDotenv(r io.Reader) (MapSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := dotenvParser{input: string(data), line: 1}
+	values := make(MapSource)
+	for {
+		key, value, found, err := parser.next()
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return values, nil
+		}
+		values[key] = value
+	}
+}
+
+// dotenvParser — the state of dotenv parsing.
+type dotenvParser struct {
+	input string
+	pos   int
+	line  int
+}
+
+// next returns the next key-value pair, found is false at the end of the input.
+func (parser *dotenvParser) next() (key, value string, found bool, err error) {
+	for {
+		parser.skipSpaces()
+		if parser.pos >= len(parser.input) {
+			return "", "", false, nil
+		}
+
+		switch parser.input[parser.pos] {
+		case '\n':
+			parser.pos++
+			parser.line++
+			continue
+		case '#':
+			parser.skipLine()
+			continue
+		}
+
+		break
+	}
+
+	key = parser.readKey()
+	if key == exportPrefix && parser.pos < len(parser.input) && isDotenvSpace(parser.input[parser.pos]) {
+		parser.skipSpaces()
+		key = parser.readKey()
+	}
+	if key == "" {
+		return "", "", false, parser.fail("a variable name is expected")
+	}
+
+	parser.skipSpaces()
+	if parser.pos >= len(parser.input) || parser.input[parser.pos] != '=' {
+		return "", "", false, parser.fail("'=' is expected after '" + key + "'")
+	}
+	parser.pos++
+	parser.skipSpaces()
+
+	value, err = parser.readValue()
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return key, value, true, nil
+}
+
+// readKey reads a variable name.
+func (parser *dotenvParser) readKey() string {
+	start := parser.pos
+	for parser.pos < len(parser.input) && isDotenvKeyChar(parser.input[parser.pos]) {
+		parser.pos++
+	}
+
+	return parser.input[start:parser.pos]
+}
+
+// readValue reads a quoted or an unquoted value and the rest of the line.
+func (parser *dotenvParser) readValue() (string, error) {
+	if parser.pos >= len(parser.input) {
+		return "", nil
+	}
+
+	var value string
+	var err error
+	switch parser.input[parser.pos] {
+	case '\'':
+		value, err = parser.readSingleQuoted()
+	case '"':
+		value, err = parser.readDoubleQuoted()
+	default:
+		return parser.readUnquoted(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// only spaces and a comment may follow the closing quote
+	parser.skipSpaces()
+	if parser.pos < len(parser.input) {
+		switch parser.input[parser.pos] {
+		case '\n':
+		case '#':
+			parser.skipLine()
+		default:
+			return "", parser.fail("unexpected character after the closing quote")
+		}
+	}
+
+	return value, nil
+}
+
+// readUnquoted reads a value up to the end of the line or an inline comment.
+func (parser *dotenvParser) readUnquoted() string {
+	start := parser.pos
+	for parser.pos < len(parser.input) && parser.input[parser.pos] != '\n' {
+		if parser.input[parser.pos] == '#' && isDotenvSpace(parser.input[parser.pos-1]) {
+			break
+		}
+		parser.pos++
+	}
+	value := strings.TrimRight(parser.input[start:parser.pos], " \t\r")
+	parser.skipLine()
+
+	return value
+}
+
+// readSingleQuoted reads a value up to the closing single quote, the value is taken literally.
+func (parser *dotenvParser) readSingleQuoted() (string, error) {
+	line := parser.line
+	parser.pos++
+	start := parser.pos
+	for ; parser.pos < len(parser.input); parser.pos++ {
+		switch parser.input[parser.pos] {
+		case '\'':
+			value := parser.input[start:parser.pos]
+			parser.pos++
+			return value, nil
+		case '\n':
+			parser.line++
+		}
+	}
+
+	return "", &DotenvSyntaxError{Line: line, Reason: "the single quote is not closed"}
+}
+
+// readDoubleQuoted reads a value up to the closing double quote processing the escape sequences.
+func (parser *dotenvParser) readDoubleQuoted() (string, error) {
+	line := parser.line
+	parser.pos++
+	var value strings.Builder
+	for ; parser.pos < len(parser.input); parser.pos++ {
+		char := parser.input[parser.pos]
+		switch char {
+		case '"':
+			parser.pos++
+			return value.String(), nil
+		case '\n':
+			parser.line++
+		case '\\':
+			if parser.pos+1 < len(parser.input) {
+				parser.pos++
+				if parser.input[parser.pos] == '\n' {
+					parser.line++
+				}
+				value.WriteString(unescapeDotenv(parser.input[parser.pos]))
+				continue
+			}
+		}
+		value.WriteByte(char)
+	}
+
+	return "", &DotenvSyntaxError{Line: line, Reason: "the double quote is not closed"}
+}
+
+// unescapeDotenv returns the character that the escape sequence stands for.
+func unescapeDotenv(char byte) string {
+	switch char {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$', '\'':
+		return string(char)
+	case '\n':
+		// an escaped line break continues the line
+		return ""
+	default:
+		return "\\" + string(char)
+	}
+}
+
+// skipSpaces skips spaces and tabs.
+func (parser *dotenvParser) skipSpaces() {
+	for parser.pos < len(parser.input) && isDotenvSpace(parser.input[parser.pos]) {
+		parser.pos++
+	}
+}
+
+// skipLine skips everything up to the end of the line.
+func (parser *dotenvParser) skipLine() {
+	for parser.pos < len(parser.input) && parser.input[parser.pos] != '\n' {
+		parser.pos++
+	}
+}
+
+// fail forms a syntax error on the current line.
+func (parser *dotenvParser) fail(reason string) error {
+	return &DotenvSyntaxError{Line: parser.line, Reason: reason}
+}
+
+func isDotenvSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\r'
+}
+
+func isDotenvKeyChar(char byte) bool {
+	return char == '_' || char == '.' || char == '-' ||
+		'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || '0' <= char && char <= '9'
+}
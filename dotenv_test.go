@@ -0,0 +1,140 @@
+package settings
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    MapSource
+		wantErr error
+	}{
+		{
+			name:  "plain values",
+			input: "PORT=80\nDB = db/file \n",
+			want:  MapSource{"PORT": "80", "DB": "db/file"},
+		},
+		{
+			name:  "comments and empty lines",
+			input: "# comment\n\n  # indented comment\nPORT=80 # inline\nHASH=a#b\nEMPTY=\n",
+			want:  MapSource{"PORT": "80", "HASH": "a#b", "EMPTY": ""},
+		},
+		{
+			name:  "export keyword",
+			input: "export PORT=80\nexport=yes\n",
+			want:  MapSource{"PORT": "80", "export": "yes"},
+		},
+		{
+			name:  "single quotes",
+			input: `KEY='a \n "b" # c' # comment`,
+			want:  MapSource{"KEY": `a \n "b" # c`},
+		},
+		{
+			name:  "double quotes with escapes",
+			input: `KEY="a\nb\t\"c\"\\ \$d \q"`,
+			want:  MapSource{"KEY": "a\nb\t\"c\"\\ $d \\q"},
+		},
+		{
+			name:  "multiline double quotes",
+			input: "KEY=\"line 1\nline 2\"\nNEXT=1\r\n",
+			want:  MapSource{"KEY": "line 1\nline 2", "NEXT": "1"},
+		},
+		{
+			name:  "multiline single quotes",
+			input: "KEY='line 1\nline 2'",
+			want:  MapSource{"KEY": "line 1\nline 2"},
+		},
+		{
+			name:  "the last value wins",
+			input: "KEY=1\nKEY=2",
+			want:  MapSource{"KEY": "2"},
+		},
+		{
+			name:    "unclosed double quote",
+			input:   "A=1\nKEY=\"value\nB=2",
+			wantErr: NewDotenvSyntaxError(2, "the double quote is not closed"),
+		},
+		{
+			name:    "unclosed single quote",
+			input:   "KEY='value",
+			wantErr: NewDotenvSyntaxError(1, "the single quote is not closed"),
+		},
+		{
+			name:    "no equal sign",
+			input:   "A=1\n\nKEY value",
+			wantErr: NewDotenvSyntaxError(3, "'=' is expected after 'KEY'"),
+		},
+		{
+			name:    "no key",
+			input:   "=value",
+			wantErr: NewDotenvSyntaxError(1, "a variable name is expected"),
+		},
+		{
+			name:    "garbage after quote",
+			input:   `KEY="value"x`,
+			wantErr: NewDotenvSyntaxError(1, "unexpected character after the closing quote"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv(strings.NewReader(tt.input))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || err.Error() != tt.wantErr.Error() {
+					t.Errorf("ParseDotenv() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				var target *DotenvSyntaxError
+				if !errors.As(err, &target) || target.Line != tt.wantErr.(*DotenvSyntaxError).Line {
+					t.Errorf("ParseDotenv() error = %#v, want *DotenvSyntaxError on the same line", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseDotenv() unexpected error = %v", err)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseDotenv() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("DB_URL=\"postgres://db\"\nexport DB_TIMEOUT=1s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := Dotenv(path)
+	if err != nil {
+		t.Fatalf("Dotenv() unexpected error = %v", err)
+	}
+
+	loader := Loader{Sources: []Source{source}}
+	var got simpleConfig
+	if err = loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if got.DBURL != "postgres://db" || got.Timeout.String() != "1s" {
+		t.Errorf("Load() got = %+v", got)
+	}
+
+	if origin := loader.Provenance()["simpleConfig.DBURL"]; origin.Layer != path {
+		t.Errorf("Provenance() layer = %s, want %s", origin.Layer, path)
+	}
+
+	if _, err = Dotenv(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Dotenv() error = %v, want os.ErrNotExist", err)
+	}
+}
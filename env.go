@@ -1,20 +1,27 @@
 package settings
 
 import (
-	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Load loads settings to a struct.
+// Load loads settings to a struct from the environment variables.
 func Load(settings any) error {
-	engine, nestedStruct := settings.(*Engine)
-	if !nestedStruct {
-		engine = newEngine(settings)
-	}
+	return LoadFrom(settings, Environment)
+}
 
+// LoadFrom loads settings to a struct from the sources. The sources are consulted in
+// the given order, the first one that has a value for a key wins. If no sources are
+// passed, the environment variables are used.
+func LoadFrom(settings any, sources ...Source) error {
+	return (&Loader{Sources: sources}).Load(settings)
+}
+
+// load processes the fields of the struct and the nested structs.
+func (engine *Engine) load() error {
 	err := engine.getStruct()
 	if err != nil {
 		return err
@@ -28,129 +35,443 @@ func Load(settings any) error {
 			continue
 		}
 
-		if engine.Field.value.Kind() == reflect.Ptr || //nolint:nestif
-			engine.Field.value.Kind() == reflect.Struct {
-			// we check whether the field is pointer or struct
-
-			err = Load(&Engine{
-				Value: engine.Field.value,
-				Type:  engine.Field.value.Type(),
-			})
-			if err != nil {
+		if err = engine.loadField(); err != nil {
+			if err = engine.fail(err); err != nil {
 				return err
 			}
-			continue
+		}
+	}
+
+	return nil
+}
+
+// loadField processes the current field.
+func (engine *Engine) loadField() error {
+	if engine.loader.isNested(engine.Field.value.Type()) {
+		// we check whether the field is a struct or a pointer to it
+
+		return engine.nested().load()
+	}
+
+	// if a field has no env tag, we pass such a field
+	if !engine.Field.hasEnvTag {
+		return nil
+	}
+
+	// the tags that cannot be used are reported even if the value is absent
+	if engine.Field.tagError != nil {
+		return engine.Field.tagError
+	}
+
+	// the slices and maps of structs are loaded from the groups of keys rather than a single value
+	if engine.Field.value.Kind() == reflect.Slice && engine.loader.isNested(engine.Field.value.Type().Elem()) {
+		return engine.loadStructs()
+	}
+	if engine.Field.value.Kind() == reflect.Map && engine.loader.isNested(engine.Field.value.Type().Elem()) {
+		return engine.loadStructMap()
+	}
+
+	// we check if it is required
+	engine.validateRequired()
+
+	// if a field has env tag, but the env was not found, and if it is required
+	// we return error
+	var key string
+	engine.Field.envValue, key, engine.Field.hasEnvValue = engine.lookup(engine.Field.envNames)
+	if engine.probing() {
+		return nil
+	}
+
+	if engine.Field.hasEnvValue && engine.Field.deprecated && key != engine.Field.envTag {
+		engine.warnDeprecated(key)
+	}
+
+	if !engine.Field.hasEnvValue {
+		if engine.Field.hasDefaultSetting {
+			// substitute the envValue with default setting
+			engine.Field.envValue = engine.Field.defaultSetting
+			engine.record(Origin{Layer: defaultSetting, Key: engine.Field.envTag})
 		} else {
-			// if a field has no env tag, we pass such a field
-			if !engine.Field.hasEnvTag {
+			if engine.Field.required {
+				return engine.validationFailed()
+			}
+			return nil
+		}
+	}
+
+	if !engine.Field.value.IsValid() {
+		return ErrInternalFailure
+	}
+
+	// We are checking if the field is addressable
+	if !engine.Field.value.CanSet() {
+		return ErrNotAddressableField
+	}
+
+	return engine.setValue(engine.Field.value, engine.Field.envValue, engine.fieldPath())
+}
+
+// loadStructs loads the slice of nested structs from the numbered keys, e.g. UPSTREAM_0_HOST,
+// UPSTREAM_0_PORT, UPSTREAM_1_HOST. The elements are read until an index has no values in
+// the sources, every element is loaded and validated as a nested struct.
+func (engine *Engine) loadStructs() error {
+	sliceType := engine.Field.value.Type()
+	slice := reflect.MakeSlice(sliceType, 0, 0)
+	for i := 0; ; i++ {
+		path := engine.fieldPath() + "[" + strconv.Itoa(i) + "]"
+		prefix := engine.Field.envTag + keySeparator + strconv.Itoa(i) + keySeparator
+
+		// the element exists if any of its keys is found
+		keys := engine.keys
+		if !engine.probing() {
+			keys = new([]string)
+		}
+		if err := engine.element(reflect.New(sliceType.Elem()).Elem(), path, prefix, keys).load(); err != nil {
+			return err
+		}
+		if engine.probing() || !engine.exists(*keys) {
+			break
+		}
+
+		element, err := engine.loadElement(sliceType.Elem(), path, prefix)
+		if err != nil {
+			return err
+		}
+
+		slice = reflect.Append(slice, element)
+	}
+
+	if slice.Len() != 0 {
+		engine.Field.value.Set(slice)
+	}
+
+	return nil
+}
+
+// loadStructMap loads the map of nested structs which keys are discovered in the sources that
+// implement Enumerator: TENANT_ACME_DSN and TENANT_GLOBEX_DSN give the keys acme and globex.
+// Every element is loaded and validated as a nested struct.
+func (engine *Engine) loadStructMap() error {
+	mapType := engine.Field.value.Type()
+	if mapType.Key().Kind() != reflect.String {
+		return engine.unsupportedField(engine.fieldPath(), mapType)
+	}
+
+	// the keys of a map cannot be probed, so it adds no env names to the enclosing struct
+	if engine.probing() {
+		return nil
+	}
+
+	// the env names of an element relative to its prefix, e.g. DSN
+	var names []string
+	if err := engine.element(reflect.New(mapType.Elem()).Elem(), engine.fieldPath(), "", &names).load(); err != nil {
+		return err
+	}
+
+	prefix := engine.Field.envTag + keySeparator
+	segments := discoverSegments(engine.loader.sources(), prefix, names)
+	if len(segments) == 0 {
+		return nil
+	}
+
+	result := reflect.MakeMapWithSize(mapType, len(segments))
+	for _, segment := range segments {
+		key := strings.ToLower(segment)
+		element, err := engine.loadElement(mapType.Elem(), engine.fieldPath()+"["+key+"]", prefix+segment+keySeparator)
+		if err != nil {
+			return err
+		}
+
+		result.SetMapIndex(reflect.ValueOf(key).Convert(mapType.Key()), element)
+	}
+
+	engine.Field.value.Set(result)
+
+	return nil
+}
+
+// loadElement loads and validates a struct element of the current slice or map field.
+func (engine *Engine) loadElement(elementType reflect.Type, path, prefix string) (reflect.Value, error) {
+	element := reflect.New(elementType).Elem()
+	if err := engine.element(element, path, prefix, nil).load(); err != nil {
+		return element, err
+	}
+
+	if err := engine.loader.unreported(engine.Validate.Struct(element.Interface()), path); err != nil {
+		if err = engine.fail(err); err != nil {
+			return element, err
+		}
+	}
+
+	return element, nil
+}
+
+// discoverSegments returns the sorted distinct segments that stand between the prefix and one
+// of the element env names in the keys of the sources. The longest matching name wins, so
+// TENANT_ACME_DB_HOST gives ACME if the element has both HOST and DB_HOST.
+func discoverSegments(sources []Source, prefix string, names []string) []string {
+	seen := make(map[string]bool)
+	var segments []string
+	for _, source := range sources {
+		enumerator, ok := source.(Enumerator)
+		if !ok {
+			continue
+		}
+
+		for _, key := range enumerator.Keys() {
+			rest, ok := strings.CutPrefix(key, prefix)
+			if !ok {
 				continue
 			}
 
-			// we check if it is required
-			engine.validateRequired()
-
-			// if a field has env tag, but the env was not found, and if it is required
-			// we return error
-			engine.Field.envValue, engine.Field.hasEnvValue = os.LookupEnv(engine.Field.envTag)
-			if !engine.Field.hasEnvValue {
-				if engine.Field.hasDefaultSetting {
-					// substitute the envValue with default setting
-					engine.Field.envValue = engine.Field.defaultSetting
-				} else {
-					if engine.Field.required {
-						return engine.validationFailed()
-					}
-					continue
+			segment := ""
+			for _, name := range names {
+				candidate, ok := strings.CutSuffix(rest, keySeparator+name)
+				if ok && candidate != "" && (segment == "" || len(candidate) < len(segment)) {
+					segment = candidate
 				}
 			}
 
-			if !engine.Value.Field(i).IsValid() {
-				return ErrInternalFailure
+			if segment != "" && !seen[segment] {
+				seen[segment] = true
+				segments = append(segments, segment)
 			}
+		}
+	}
+	sort.Strings(segments)
+
+	return segments
+}
+
+// setValue converts the raw value to the type of the value and sets it. The value is either
+// the current field or an element of it, the path names the value in the errors.
+func (engine *Engine) setValue(value reflect.Value, raw, path string) error {
+	// the registered parsers take precedence over everything else
+	if parse, ok := engine.loader.parsers[value.Type()]; ok {
+		parsed, err := parse(raw)
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
+
+		value.Set(parsed)
+		return nil
+	}
+
+	// the types that have a dedicated conversion, e.g. time.Time or net.IP
+	if converted, err := setBuiltin(value, raw, engine.Field.layout); converted {
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
+		return nil
+	}
+
+	// the pointer is allocated only if a value was found, so nil tells that the setting is absent
+	if value.Kind() == reflect.Ptr {
+		element := reflect.New(value.Type().Elem())
+		if err := engine.setValue(element.Elem(), raw, path); err != nil {
+			return err
+		}
+
+		value.Set(element)
+		return nil
+	}
+
+	// the types that decode themselves take precedence over the built-in conversion
+	if decoded, err := decode(value, raw); decoded {
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
+		return nil
+	}
 
-			// We are checking if the field is addressable
-			if !engine.Value.Field(i).CanSet() {
-				return ErrNotAddressableField
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Slice:
+		elementType := value.Type().Elem()
+		if elementType.Kind() == reflect.Uint8 {
+			value.SetBytes([]byte(raw))
+			return nil
+		}
+		if !engine.isElementType(elementType) {
+			return engine.unsupportedField(path, value.Type())
+		}
+
+		// every element goes through the same conversion as a single value
+		elements := engine.Field.split(raw)
+		slice := reflect.MakeSlice(value.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := engine.setValue(slice.Index(i), element, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
 			}
+		}
 
-			switch engine.Field.value.Kind() { //nolint:exhaustive
-			case reflect.Slice:
-				switch engine.Field.value.Type().Elem().Kind() {
-				case reflect.Uint8:
-					engine.Field.value.SetBytes([]byte(engine.Field.envValue))
-				case reflect.String:
-					stringSlice := strings.Split(engine.Field.envValue, ",")
-					engine.Field.value.Set(reflect.ValueOf(stringSlice))
-				default:
-					return unsupportedFieldError(engine.Field.envTag)
-				}
-			case reflect.String:
-				engine.Field.value.SetString(engine.Field.envValue)
-			case reflect.Float64:
-				engine.Field.float64Value, err = strconv.ParseFloat(engine.Field.envValue, 64)
-				if err != nil {
-					return incorrectFieldValueError(engine.Field.envTag)
-				}
+		value.Set(slice)
+	case reflect.Map:
+		keyType, elementType := value.Type().Key(), value.Type().Elem()
+		if !engine.isElementType(keyType) || !engine.isElementType(elementType) {
+			return engine.unsupportedField(path, value.Type())
+		}
 
-				engine.Field.value.SetFloat(engine.Field.float64Value)
+		// the pairs are split as a list, then every pair is split to the key and the value
+		pairs, malformed, ok := engine.Field.pairs(raw)
+		if !ok {
+			return engine.incorrectFieldValue(path, malformed, value.Type(), ErrNoKeyValueSeparator)
+		}
 
-			case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-				engine.Field.uint64Value, err = strconv.ParseUint(engine.Field.envValue, 10, 64)
-				if err != nil {
-					return incorrectFieldValueError(engine.Field.envTag)
-				}
+		result := reflect.MakeMapWithSize(value.Type(), len(pairs))
+		for _, pair := range pairs {
+			rawKey, rawElement := pair[0], pair[1]
+			elementPath := path + "[" + rawKey + "]"
+			if engine.Field.secret {
+				elementPath = path + "[" + redacted + "]"
+			}
+			key := reflect.New(keyType).Elem()
+			if err := engine.setValue(key, rawKey, elementPath); err != nil {
+				return err
+			}
 
-				// check if whether the value exceeds the type maximum or not
-				if engine.Field.exceedsMaximumUint() {
-					return incorrectFieldValueError(engine.Field.envTag)
-				}
+			element := reflect.New(elementType).Elem()
+			if err := engine.setValue(element, rawElement, elementPath); err != nil {
+				return err
+			}
 
-				engine.Field.value.SetUint(engine.Field.uint64Value)
-
-			case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-				if engine.Field.value.Kind() == reflect.Int64 &&
-					engine.Field.value.Type().String() == duration {
-					// check if it is time.Duration
-
-					engine.Field.durationValue, err = time.ParseDuration(engine.Field.envValue)
-					if err != nil {
-						return incorrectFieldValueError(engine.Field.envTag)
-					}
-					engine.Field.int64Value = engine.Field.durationValue.Nanoseconds()
-				} else {
-					engine.Field.int64Value, err = strconv.ParseInt(engine.Field.envValue, 10, 64)
-					if err != nil {
-						return err
-					}
-
-					if engine.Field.notInIntRange() {
-						return incorrectFieldValueError(engine.Field.envTag)
-					}
-				}
+			result.SetMapIndex(key, element)
+		}
 
-				engine.Field.value.SetInt(engine.Field.int64Value)
+		value.Set(result)
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Float32, reflect.Float64:
+		// the bit size makes ParseFloat report the values that overflow float32
+		floatValue, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
 
-			case reflect.Bool:
-				engine.Field.value.SetBool(strings.ToLower(engine.Field.envValue) == "true")
-			default:
-				return unsupportedFieldError(engine.Field.value.Type().Name())
+		value.SetFloat(floatValue)
+
+	case reflect.Complex64, reflect.Complex128:
+		complexValue, err := strconv.ParseComplex(raw, value.Type().Bits())
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
+
+		value.SetComplex(complexValue)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		var uintValue uint64
+		var err error
+		if engine.Field.unit == bytesUnit {
+			uintValue, err = parseByteSizeUint(raw)
+		} else {
+			uintValue, err = strconv.ParseUint(raw, engine.Field.base, 64)
+		}
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
+		}
+
+		// check if whether the value exceeds the type maximum or not
+		if exceedsMaximumUint(value.Kind(), uintValue) {
+			return engine.incorrectFieldValue(path, raw, value.Type(), rangeError("ParseUint", raw))
+		}
+
+		value.SetUint(uintValue)
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		var intValue int64
+		if value.Type() == durationType {
+			durationValue, err := time.ParseDuration(raw)
+			if err != nil {
+				return engine.incorrectFieldValue(path, raw, value.Type(), err)
+			}
+			intValue = durationValue.Nanoseconds()
+		} else {
+			var err error
+			if engine.Field.unit == bytesUnit {
+				intValue, err = parseByteSizeInt(raw)
+			} else {
+				intValue, err = strconv.ParseInt(raw, engine.Field.base, 64)
+			}
+			if err != nil {
+				return engine.incorrectFieldValue(path, raw, value.Type(), err)
+			}
+
+			if notInIntRange(value.Kind(), intValue) {
+				return engine.incorrectFieldValue(path, raw, value.Type(), rangeError("ParseInt", raw))
 			}
 		}
-	}
 
-	if !nestedStruct {
-		if err = engine.Validate.Struct(engine.Value.Interface()); err != nil {
-			return err
+		value.SetInt(intValue)
+
+	case reflect.Bool:
+		if engine.loader.LegacyBool {
+			value.SetBool(strings.ToLower(raw) == "true")
+			break
 		}
 
-		if err = runCustomValidation(engine); err != nil {
-			return err
+		boolValue, err := parseBool(raw)
+		if err != nil {
+			return engine.incorrectFieldValue(path, raw, value.Type(), err)
 		}
+
+		value.SetBool(boolValue)
+	default:
+		return engine.unsupportedField(path, value.Type())
 	}
 
 	return nil
 }
 
+// isScalarKind returns true if a value of the kind is converted from a single string.
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind { //nolint:exhaustive
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+// isConvertible returns true if a value of the type is converted by a registered parser
+// or decodes itself rather than being processed as a nested struct.
+func (loader *Loader) isConvertible(valueType reflect.Type) bool {
+	_, registered := loader.parsers[valueType]
+	return registered || isBuiltinType(valueType) || isDecodable(valueType)
+}
+
+// isNested returns true if a value of the type is loaded as a nested struct, i.e. it is
+// a struct or a pointer to it that is not converted from a single string.
+func (loader *Loader) isNested(valueType reflect.Type) bool {
+	if loader.isConvertible(valueType) {
+		return false
+	}
+
+	switch valueType.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		return true
+	case reflect.Ptr:
+		return loader.isNested(valueType.Elem())
+	default:
+		return false
+	}
+}
+
+// isElementType returns true if the type can be an element of a list or a map.
+func (engine *Engine) isElementType(elementType reflect.Type) bool {
+	if elementType.Kind() == reflect.Ptr {
+		return engine.isElementType(elementType.Elem())
+	}
+
+	return isScalarKind(elementType.Kind()) || engine.loader.isConvertible(elementType)
+}
+
+// rangeError forms the parse error of a value that does not fit the field type.
+func rangeError(function, value string) error {
+	return &strconv.NumError{Func: function, Num: value, Err: strconv.ErrRange}
+}
+
 func runCustomValidation(engine *Engine) error {
 	if to, validatable := engine.Value.Interface().(interface {
 		Validate() error
@@ -2,8 +2,22 @@ package settings
 
 import (
 	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	lookalikenet "github.com/kaatinga/settings/internal/lookalike/net"
+	lookaliketime "github.com/kaatinga/settings/internal/lookalike/time"
+	lookalikeurl "github.com/kaatinga/settings/internal/lookalike/url"
 )
 
 type emptySettings struct{}
@@ -545,3 +559,1133 @@ func createFreshInstance(original any) any {
 		return original
 	}
 }
+
+type boolConfig struct {
+	Enabled bool `env:"ENABLED"`
+}
+
+func TestLoadBool(t *testing.T) {
+	tests := []struct {
+		value   string
+		legacy  bool
+		want    bool
+		wantErr error
+	}{
+		{value: "true", want: true},
+		{value: "TRUE", want: true},
+		{value: "1", want: true},
+		{value: "t", want: true},
+		{value: "yes", want: true},
+		{value: "Y", want: true},
+		{value: "on", want: true},
+		{value: "false", want: false},
+		{value: "0", want: false},
+		{value: "F", want: false},
+		{value: "No", want: false},
+		{value: "n", want: false},
+		{value: "OFF", want: false},
+		{value: "ture", wantErr: NewIncorrectFieldValueError("ENABLED")},
+		{value: "2", wantErr: NewIncorrectFieldValueError("ENABLED")},
+		{value: "", wantErr: NewIncorrectFieldValueError("ENABLED")},
+		{value: "True", legacy: true, want: true},
+		{value: "1", legacy: true, want: false},
+		{value: "ture", legacy: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			loader := Loader{Sources: []Source{MapSource{"ENABLED": tt.value}}, LegacyBool: tt.legacy}
+
+			var got boolConfig
+			err := loader.Load(&got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+
+			if got.Enabled != tt.want {
+				t.Errorf("Load() got = %v, want %v", got.Enabled, tt.want)
+			}
+		})
+	}
+}
+
+type numericConfig struct {
+	Float32    float32    `env:"FLOAT32"`
+	Float64    float64    `env:"FLOAT64"`
+	Complex64  complex64  `env:"COMPLEX64"`
+	Complex128 complex128 `env:"COMPLEX128"`
+}
+
+func TestLoadFloatAndComplex(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		want   numericConfig
+	}{
+		{
+			name:   "float32",
+			source: MapSource{"FLOAT32": "3.4e38"},
+			want:   numericConfig{Float32: 3.4e38},
+		},
+		{
+			name:   "negative float32",
+			source: MapSource{"FLOAT32": "-0.25"},
+			want:   numericConfig{Float32: -0.25},
+		},
+		{
+			name:   "float64 beyond float32",
+			source: MapSource{"FLOAT64": "3.5e38"},
+			want:   numericConfig{Float64: 3.5e38},
+		},
+		{
+			name:   "complex",
+			source: MapSource{"COMPLEX64": "1+2i", "COMPLEX128": "(-1.5-0.5i)"},
+			want:   numericConfig{Complex64: 1 + 2i, Complex128: -1.5 - 0.5i},
+		},
+		{
+			name:   "real complex",
+			source: MapSource{"COMPLEX128": "4"},
+			want:   numericConfig{Complex128: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got numericConfig
+			if err := LoadFrom(&got, tt.source); err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("LoadFrom() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type typedSlicesConfig struct {
+	Ints      []int           `env:"INTS"`
+	Int8s     []int8          `env:"INT8S"`
+	Uints     []uint16        `env:"UINTS"`
+	Floats    []float32       `env:"FLOATS"`
+	Durations []time.Duration `env:"DURATIONS"`
+	Bools     []bool          `env:"BOOLS"`
+	Complexes []complex128    `env:"COMPLEXES"`
+	Bytes     []byte          `env:"BYTES"`
+}
+
+func TestLoadTypedSlices(t *testing.T) {
+	source := MapSource{
+		"INTS":      "1,-2,3",
+		"INT8S":     "127,-128",
+		"UINTS":     "80,65535",
+		"FLOATS":    "0.5,-1e3",
+		"DURATIONS": "1s,2m,3h",
+		"BOOLS":     "true,no,1",
+		"COMPLEXES": "1+1i,2",
+		"BYTES":     "1,2",
+	}
+
+	var got typedSlicesConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	want := typedSlicesConfig{
+		Ints:      []int{1, -2, 3},
+		Int8s:     []int8{127, -128},
+		Uints:     []uint16{80, 65535},
+		Floats:    []float32{0.5, -1e3},
+		Durations: []time.Duration{time.Second, 2 * time.Minute, 3 * time.Hour},
+		Bools:     []bool{true, false, true},
+		Complexes: []complex128{1 + 1i, 2},
+		Bytes:     []byte("1,2"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFrom() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadTypedSlicesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    MapSource
+		path      string
+		value     string
+		fieldType string
+	}{
+		{
+			name:      "int out of range",
+			source:    MapSource{"INT8S": "1,2,128"},
+			path:      "typedSlicesConfig.Int8s[2]",
+			value:     "128",
+			fieldType: "int8",
+		},
+		{
+			name:      "bad duration",
+			source:    MapSource{"DURATIONS": "1s,later"},
+			path:      "typedSlicesConfig.Durations[1]",
+			value:     "later",
+			fieldType: "time.Duration",
+		},
+		{
+			name:      "negative uint",
+			source:    MapSource{"UINTS": "-1"},
+			path:      "typedSlicesConfig.Uints[0]",
+			value:     "-1",
+			fieldType: "uint16",
+		},
+		{
+			name:      "bad bool",
+			source:    MapSource{"BOOLS": "yes,maybe"},
+			path:      "typedSlicesConfig.Bools[1]",
+			value:     "maybe",
+			fieldType: "bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&typedSlicesConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Path != tt.path || target.Value != tt.value || target.Type != tt.fieldType {
+				t.Errorf("IncorrectFieldValueError = %+v, want path %s, value %s and type %s",
+					target, tt.path, tt.value, tt.fieldType)
+			}
+		})
+	}
+}
+
+type separatorConfig struct {
+	Default    []string        `env:"LIST"`
+	Semicolon  []string        `env:"LIST"      sep:";"`
+	Pipe       []int           `env:"PIPES"     separator:"|"  trim:"true"`
+	Whitespace []string        `env:"WORDS"     sep:" "`
+	Trimmed    []string        `env:"LIST"      trim:"true"`
+	NoEmpty    []string        `env:"EMPTIES"   skipempty:"true" trim:"true"`
+	Durations  []time.Duration `env:"DURATIONS" sep:";" skipempty:"true" trim:"true"`
+}
+
+func TestLoadSliceSeparators(t *testing.T) {
+	source := MapSource{
+		"LIST":      "a, b;c",
+		"PIPES":     " 1 | 2|3 ",
+		"WORDS":     " one\ttwo  three\n",
+		"EMPTIES":   "a,, ,b,",
+		"DURATIONS": "1s; ;2s;",
+	}
+
+	var got separatorConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	want := separatorConfig{
+		Default:    []string{"a", " b;c"},
+		Semicolon:  []string{"a, b", "c"},
+		Pipe:       []int{1, 2, 3},
+		Whitespace: []string{"one", "two", "three"},
+		Trimmed:    []string{"a", "b;c"},
+		NoEmpty:    []string{"a", "b"},
+		Durations:  []time.Duration{time.Second, 2 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFrom() got = %q, want %q", got, want)
+	}
+}
+
+type mapConfig struct {
+	Labels    map[string]string        `env:"LABELS"`
+	Weights   map[string]int           `env:"WEIGHTS"   trim:"true"`
+	Timeouts  map[string]time.Duration `env:"TIMEOUTS"  kvsep:"=" sep:";"`
+	Flags     map[int]bool             `env:"FLAGS"     skipempty:"true"`
+	Untouched map[string]string        `env:"UNTOUCHED"`
+}
+
+func TestLoadMaps(t *testing.T) {
+	source := MapSource{
+		"LABELS":   "team:core,tier:1,url:http://host",
+		"WEIGHTS":  "a : 1, b:-2",
+		"TIMEOUTS": "read=1s;write=2m",
+		"FLAGS":    "1:yes,,2:off,",
+	}
+
+	var got mapConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	want := mapConfig{
+		Labels:   map[string]string{"team": "core", "tier": "1", "url": "http://host"},
+		Weights:  map[string]int{"a": 1, "b": -2},
+		Timeouts: map[string]time.Duration{"read": time.Second, "write": 2 * time.Minute},
+		Flags:    map[int]bool{1: true, 2: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFrom() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadMapsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		path   string
+		value  string
+		cause  error
+	}{
+		{
+			name:   "no separator",
+			source: MapSource{"LABELS": "team:core,tier"},
+			path:   "mapConfig.Labels",
+			value:  "tier",
+			cause:  ErrNoKeyValueSeparator,
+		},
+		{
+			name:   "bad value",
+			source: MapSource{"WEIGHTS": "a:1,b:heavy"},
+			path:   "mapConfig.Weights[b]",
+			value:  "heavy",
+			cause:  strconv.ErrSyntax,
+		},
+		{
+			name:   "bad key",
+			source: MapSource{"FLAGS": "one:true"},
+			path:   "mapConfig.Flags[one]",
+			value:  "one",
+			cause:  strconv.ErrSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&mapConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Path != tt.path || target.Value != tt.value || !errors.Is(err, tt.cause) {
+				t.Errorf("IncorrectFieldValueError = %+v, want path %s, value %s and cause %v",
+					target, tt.path, tt.value, tt.cause)
+			}
+		})
+	}
+}
+
+type timeConfig struct {
+	Start    time.Time      `env:"START"`
+	Date     time.Time      `env:"DATE"     layout:"2006-01-02"`
+	Kitchen  time.Time      `env:"KITCHEN"  layout:"Kitchen"`
+	Location *time.Location `env:"LOCATION"`
+	Weekday  time.Weekday   `env:"WEEKDAY"`
+	Month    time.Month     `env:"MONTH"`
+	Days     []time.Weekday `env:"DAYS"`
+	Holidays []time.Time    `env:"HOLIDAYS" layout:"DateOnly"`
+	Unset    *time.Location `env:"UNSET"`
+}
+
+func TestLoadTimeTypes(t *testing.T) {
+	source := MapSource{
+		"START":    "2024-05-01T10:00:00.5+02:00",
+		"DATE":     "2024-12-31",
+		"KITCHEN":  "3:04PM",
+		"LOCATION": "Europe/Berlin",
+		"WEEKDAY":  "wed",
+		"MONTH":    "February",
+		"DAYS":     "Mon,FRIDAY,0",
+		"HOLIDAYS": "2024-01-01,2024-12-25",
+	}
+
+	var got timeConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	switch {
+	case !got.Start.Equal(time.Date(2024, 5, 1, 8, 0, 0, 5e8, time.UTC)):
+		t.Errorf("Start = %v", got.Start)
+	case !got.Date.Equal(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)):
+		t.Errorf("Date = %v", got.Date)
+	case got.Kitchen.Hour() != 15 || got.Kitchen.Minute() != 4:
+		t.Errorf("Kitchen = %v", got.Kitchen)
+	case got.Location == nil || got.Location.String() != "Europe/Berlin":
+		t.Errorf("Location = %v", got.Location)
+	case got.Weekday != time.Wednesday || got.Month != time.February:
+		t.Errorf("Weekday = %v, Month = %v", got.Weekday, got.Month)
+	case !reflect.DeepEqual(got.Days, []time.Weekday{time.Monday, time.Friday, time.Sunday}):
+		t.Errorf("Days = %v", got.Days)
+	case len(got.Holidays) != 2 || got.Holidays[1].Month() != time.December:
+		t.Errorf("Holidays = %v", got.Holidays)
+	case got.Unset != nil:
+		t.Errorf("Unset = %v, want nil", got.Unset)
+	}
+}
+
+func TestLoadTimeTypesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		path   string
+	}{
+		{name: "time", source: MapSource{"START": "2024-05-01"}, path: "timeConfig.Start"},
+		{name: "layout", source: MapSource{"DATE": "31.12.2024"}, path: "timeConfig.Date"},
+		{name: "location", source: MapSource{"LOCATION": "Mars/Olympus"}, path: "timeConfig.Location"},
+		{name: "weekday", source: MapSource{"WEEKDAY": "7"}, path: "timeConfig.Weekday"},
+		{name: "month", source: MapSource{"MONTH": "Smarch"}, path: "timeConfig.Month"},
+		{name: "element", source: MapSource{"DAYS": "mon,someday"}, path: "timeConfig.Days[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&timeConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Path != tt.path || target.Err == nil {
+				t.Errorf("IncorrectFieldValueError = %+v, want path %s", target, tt.path)
+			}
+		})
+	}
+}
+
+type networkConfig struct {
+	Endpoint  url.URL        `env:"ENDPOINT"`
+	Proxy     *url.URL       `env:"PROXY"`
+	IP        net.IP         `env:"IP"`
+	Network   *net.IPNet     `env:"NETWORK"`
+	Subnet    net.IPNet      `env:"SUBNET"`
+	Addr      netip.Addr     `env:"ADDR"`
+	Prefix    netip.Prefix   `env:"PREFIX"`
+	Listen    netip.AddrPort `env:"LISTEN"`
+	Allowlist []netip.Prefix `env:"ALLOWLIST"`
+	Upstreams []*url.URL     `env:"UPSTREAMS"`
+	Unset     *url.URL       `env:"UNSET"`
+}
+
+func TestLoadNetworkTypes(t *testing.T) {
+	source := MapSource{
+		"ENDPOINT":  "https://api.example.com/v1?x=1",
+		"PROXY":     "http://proxy:3128",
+		"IP":        "192.168.1.1",
+		"NETWORK":   "10.0.0.0/8",
+		"SUBNET":    "2001:db8::/32",
+		"ADDR":      "fe80::1",
+		"PREFIX":    "172.16.0.0/12",
+		"LISTEN":    "[::1]:8080",
+		"ALLOWLIST": "10.0.0.0/8,192.168.0.0/16",
+		"UPSTREAMS": "http://a:80,http://b:80",
+	}
+
+	var got networkConfig
+	if err := LoadFrom(&got, source); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	switch {
+	case got.Endpoint.Host != "api.example.com" || got.Endpoint.Query().Get("x") != "1":
+		t.Errorf("Endpoint = %v", got.Endpoint)
+	case got.Proxy == nil || got.Proxy.Port() != "3128":
+		t.Errorf("Proxy = %v", got.Proxy)
+	case !got.IP.Equal(net.IPv4(192, 168, 1, 1)):
+		t.Errorf("IP = %v", got.IP)
+	case got.Network == nil || got.Network.String() != "10.0.0.0/8":
+		t.Errorf("Network = %v", got.Network)
+	case got.Subnet.String() != "2001:db8::/32":
+		t.Errorf("Subnet = %v", got.Subnet.String())
+	case got.Addr != netip.MustParseAddr("fe80::1"):
+		t.Errorf("Addr = %v", got.Addr)
+	case got.Prefix != netip.MustParsePrefix("172.16.0.0/12"):
+		t.Errorf("Prefix = %v", got.Prefix)
+	case got.Listen.Port() != 8080 || !got.Listen.Addr().IsLoopback():
+		t.Errorf("Listen = %v", got.Listen)
+	case len(got.Allowlist) != 2 || !got.Allowlist[1].Contains(netip.MustParseAddr("192.168.5.5")):
+		t.Errorf("Allowlist = %v", got.Allowlist)
+	case len(got.Upstreams) != 2 || got.Upstreams[1].Host != "b:80":
+		t.Errorf("Upstreams = %v", got.Upstreams)
+	case got.Unset != nil:
+		t.Errorf("Unset = %v, want nil", got.Unset)
+	}
+}
+
+func TestLoadNetworkTypesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		env    string
+		path   string
+	}{
+		{name: "url", source: MapSource{"PROXY": "http://[::1"}, env: "PROXY", path: "networkConfig.Proxy"},
+		{name: "ip", source: MapSource{"IP": "192.168.1"}, env: "IP", path: "networkConfig.IP"},
+		{name: "empty ip", source: MapSource{"IP": ""}, env: "IP", path: "networkConfig.IP"},
+		{name: "cidr", source: MapSource{"NETWORK": "10.0.0.0"}, env: "NETWORK", path: "networkConfig.Network"},
+		{name: "addr", source: MapSource{"ADDR": ""}, env: "ADDR", path: "networkConfig.Addr"},
+		{name: "prefix", source: MapSource{"PREFIX": "10.0.0.0/33"}, env: "PREFIX", path: "networkConfig.Prefix"},
+		{name: "addr port", source: MapSource{"LISTEN": "localhost:80"}, env: "LISTEN", path: "networkConfig.Listen"},
+		{
+			name:   "allowlist element",
+			source: MapSource{"ALLOWLIST": "10.0.0.0/8,everyone"},
+			env:    "ALLOWLIST",
+			path:   "networkConfig.Allowlist[1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&networkConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Env != tt.env || target.Path != tt.path || target.Err == nil {
+				t.Errorf("IncorrectFieldValueError = %+v, want env %s and path %s", target, tt.env, tt.path)
+			}
+		})
+	}
+}
+
+type byteSizeConfig struct {
+	Cache  uint64   `env:"CACHE"  unit:"bytes"`
+	Buffer int32    `env:"BUFFER" unit:"bytes"`
+	Small  uint8    `env:"SMALL"  unit:"bytes"`
+	Limits []uint32 `env:"LIMITS" unit:"bytes"`
+}
+
+func TestLoadByteSizes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint64
+	}{
+		{value: "512", want: 512},
+		{value: "512B", want: 512},
+		{value: "512k", want: 512000},
+		{value: "512KB", want: 512000},
+		{value: "64MiB", want: 64 << 20},
+		{value: "64mi", want: 64 << 20},
+		{value: "1.5GB", want: 1500000000},
+		{value: "1.5 GiB", want: 3 << 29},
+		{value: "2TB", want: 2e12},
+		{value: "16EiB", want: 0},
+		{value: "15EiB", want: 15 << 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var got byteSizeConfig
+			err := LoadFrom(&got, MapSource{"CACHE": tt.value})
+			if tt.want == 0 {
+				if !errors.Is(err, strconv.ErrRange) {
+					t.Errorf("LoadFrom() error = %v, want strconv.ErrRange", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			if got.Cache != tt.want {
+				t.Errorf("LoadFrom() got = %d, want %d", got.Cache, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadByteSizesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		path   string
+		cause  error
+	}{
+		{name: "int32 overflow", source: MapSource{"BUFFER": "2GiB"}, path: "byteSizeConfig.Buffer", cause: strconv.ErrRange},
+		{name: "uint8 overflow", source: MapSource{"SMALL": "1k"}, path: "byteSizeConfig.Small", cause: strconv.ErrRange},
+		{name: "negative uint", source: MapSource{"CACHE": "-1k"}, path: "byteSizeConfig.Cache", cause: strconv.ErrRange},
+		{name: "fraction", source: MapSource{"CACHE": "1.5B"}, path: "byteSizeConfig.Cache", cause: strconv.ErrSyntax},
+		{name: "unknown unit", source: MapSource{"CACHE": "5XB"}, path: "byteSizeConfig.Cache", cause: strconv.ErrSyntax},
+		{name: "hex", source: MapSource{"CACHE": "0x10"}, path: "byteSizeConfig.Cache", cause: strconv.ErrSyntax},
+		{name: "element", source: MapSource{"LIMITS": "1k,5GB"}, path: "byteSizeConfig.Limits[1]", cause: strconv.ErrRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&byteSizeConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Path != tt.path || !errors.Is(err, tt.cause) {
+				t.Errorf("IncorrectFieldValueError = %+v, want path %s and cause %v", target, tt.path, tt.cause)
+			}
+		})
+	}
+
+	invalidTags := []struct {
+		name  string
+		value string
+		load  func(source MapSource) error
+	}{
+		{name: "unknown unit", value: "meters", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value int `env:"VALUE" unit:"meters"`
+			}{}, source)
+		}},
+		{name: "float", value: "bytes", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value float64 `env:"VALUE" unit:"bytes"`
+			}{}, source)
+		}},
+		{name: "string", value: "bytes", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value string `env:"VALUE" unit:"bytes"`
+			}{}, source)
+		}},
+		{name: "duration", value: "bytes", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value time.Duration `env:"VALUE" unit:"bytes"`
+			}{}, source)
+		}},
+		{name: "bytes", value: "bytes", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value []byte `env:"VALUE" unit:"bytes"`
+			}{}, source)
+		}},
+		{name: "float elements", value: "bytes", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Value map[string]float32 `env:"VALUE" unit:"bytes"`
+			}{}, source)
+		}},
+	}
+
+	for _, tt := range invalidTags {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, source := range []MapSource{{"VALUE": "64MiB"}, {}} {
+				err := tt.load(source)
+
+				var target *InvalidTagError
+				if !errors.As(err, &target) {
+					t.Fatalf("LoadFrom(%v) error = %v, want *InvalidTagError", source, err)
+				}
+
+				if target.Tag != "unit" || target.Value != tt.value || !strings.HasSuffix(target.Path, ".Value") {
+					t.Errorf("InvalidTagError = %+v", target)
+				}
+			}
+		})
+	}
+
+	var got byteSizeConfig
+	if err := LoadFrom(&got, MapSource{"BUFFER": "-1KiB", "LIMITS": "1k,3GiB"}); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Buffer != -1024 || !reflect.DeepEqual(got.Limits, []uint32{1000, 3 << 30}) {
+		t.Errorf("LoadFrom() got = %+v", got)
+	}
+}
+
+type numericFormatsConfig struct {
+	Pattern *regexp.Regexp `env:"PATTERN"`
+	Mode    os.FileMode    `env:"MODE"`
+	Mask    uint32         `env:"MASK"   base:"0"`
+	Offset  int16          `env:"OFFSET" base:"16"`
+	Flags   []uint16       `env:"FLAGS"  base:"2"`
+	Port    int            `env:"PORT"`
+}
+
+func TestLoadNumericFormats(t *testing.T) {
+	var got numericFormatsConfig
+	err := LoadFrom(&got, MapSource{
+		"PATTERN": `^user-\d+$`,
+		"MODE":    "0o640",
+		"MASK":    "0x1F",
+		"OFFSET":  "-7f",
+		"FLAGS":   "101,11",
+		"PORT":    "0080",
+	})
+	if err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Pattern == nil || !got.Pattern.MatchString("user-42") || got.Pattern.MatchString("admin") {
+		t.Errorf("LoadFrom() Pattern = %v", got.Pattern)
+	}
+
+	want := numericFormatsConfig{Pattern: got.Pattern, Mode: 0o640, Mask: 31, Offset: -127, Flags: []uint16{5, 3}, Port: 80}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFrom() got = %+v, want %+v", got, want)
+	}
+
+	for value, mode := range map[string]os.FileMode{"755": 0o755, "0644": 0o644, "0O600": 0o600} {
+		if err = LoadFrom(&got, MapSource{"MODE": value}); err != nil || got.Mode != mode {
+			t.Errorf("LoadFrom(%s) Mode = %v, error = %v, want %v", value, got.Mode, err, mode)
+		}
+	}
+
+	if err = LoadFrom(&got, MapSource{"MASK": "0o755"}); err != nil || got.Mask != 0o755 {
+		t.Errorf("LoadFrom() Mask = %o, error = %v, want 755", got.Mask, err)
+	}
+}
+
+func TestLoadNumericFormatsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		path   string
+		cause  error
+	}{
+		{name: "invalid pattern", source: MapSource{"PATTERN": "a(b"}, path: "numericFormatsConfig.Pattern"},
+		{name: "mode not octal", source: MapSource{"MODE": "0o789"}, path: "numericFormatsConfig.Mode", cause: strconv.ErrSyntax},
+		{name: "mode overflow", source: MapSource{"MODE": "77777777777"}, path: "numericFormatsConfig.Mode", cause: strconv.ErrRange},
+		{name: "hex without base", source: MapSource{"PORT": "0x50"}, path: "numericFormatsConfig.Port", cause: strconv.ErrSyntax},
+		{name: "hex overflow", source: MapSource{"OFFSET": "8000"}, path: "numericFormatsConfig.Offset", cause: strconv.ErrRange},
+		{name: "binary element", source: MapSource{"FLAGS": "1,2"}, path: "numericFormatsConfig.Flags[1]", cause: strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&numericFormatsConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Path != tt.path || tt.cause != nil && !errors.Is(err, tt.cause) {
+				t.Errorf("IncorrectFieldValueError = %+v, want path %s and cause %v", target, tt.path, tt.cause)
+			}
+		})
+	}
+}
+
+type pointerConfig struct {
+	Timeout *time.Duration `env:"TIMEOUT"`
+	Name    *string        `env:"NAME"`
+	Debug   *bool          `env:"DEBUG"`
+	Port    **int          `env:"PORT" default:"8080"`
+	Since   *time.Time     `env:"SINCE" layout:"DateOnly"`
+	Weights []*float64     `env:"WEIGHTS"`
+	Limit   *uint8         `env:"LIMIT"`
+}
+
+func TestLoadPointers(t *testing.T) {
+	var absent pointerConfig
+	if err := LoadFrom(&absent, MapSource{}); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if absent.Timeout != nil || absent.Name != nil || absent.Debug != nil || absent.Since != nil || absent.Weights != nil {
+		t.Errorf("LoadFrom() got = %+v, want the absent settings to stay nil", absent)
+	}
+
+	if absent.Port == nil || *absent.Port == nil || **absent.Port != 8080 {
+		t.Errorf("LoadFrom() Port = %v, want the default 8080", absent.Port)
+	}
+
+	var present pointerConfig
+	err := LoadFrom(&present, MapSource{
+		"TIMEOUT": "0s",
+		"NAME":    "",
+		"DEBUG":   "false",
+		"SINCE":   "2024-02-29",
+		"WEIGHTS": "0.5,1",
+	})
+	if err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if present.Timeout == nil || *present.Timeout != 0 {
+		t.Errorf("LoadFrom() Timeout = %v, want a pointer to 0", present.Timeout)
+	}
+
+	if present.Name == nil || *present.Name != "" || present.Debug == nil || *present.Debug {
+		t.Errorf("LoadFrom() Name = %v, Debug = %v, want pointers to the zero values", present.Name, present.Debug)
+	}
+
+	if present.Since == nil || !present.Since.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("LoadFrom() Since = %v, want 2024-02-29", present.Since)
+	}
+
+	if len(present.Weights) != 2 || *present.Weights[0] != 0.5 || *present.Weights[1] != 1 {
+		t.Errorf("LoadFrom() Weights = %v, want [0.5 1]", present.Weights)
+	}
+
+	var broken pointerConfig
+	err = LoadFrom(&broken, MapSource{"LIMIT": "300"})
+
+	var target *IncorrectFieldValueError
+	if !errors.As(err, &target) || target.Path != "pointerConfig.Limit" || !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("LoadFrom() error = %v, want an out of range Limit", err)
+	}
+
+	if broken.Limit != nil {
+		t.Errorf("LoadFrom() Limit = %v, want nil after a failed conversion", broken.Limit)
+	}
+}
+
+type upstream struct {
+	Host    string   `env:"HOST" validate:"required"`
+	Port    uint16   `env:"PORT" default:"80"`
+	Weight  int      `env:"WEIGHT" validate:"gte=0"`
+	Backups []backup `env:"BACKUP"`
+}
+
+type backup struct {
+	Host string `env:"HOST"`
+}
+
+type upstreamsConfig struct {
+	Upstreams []upstream  `env:"UPSTREAM"`
+	Replicas  []*upstream `env:"REPLICA"`
+	Ignored   []upstream
+}
+
+func TestLoadStructSlices(t *testing.T) {
+	loader := Loader{Sources: []Source{MapSource{
+		"UPSTREAM_0_HOST":          "a.local",
+		"UPSTREAM_0_BACKUP_0_HOST": "a.backup",
+		"UPSTREAM_1_HOST":          "b.local",
+		"UPSTREAM_1_PORT":          "8080",
+		"UPSTREAM_3_HOST":          "after a gap",
+		"REPLICA_0_HOST":           "replica.local",
+		"IGNORED_0_HOST":           "no env tag",
+	}}}
+
+	var got upstreamsConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	want := []upstream{
+		{Host: "a.local", Port: 80, Backups: []backup{{Host: "a.backup"}}},
+		{Host: "b.local", Port: 8080},
+	}
+	if !reflect.DeepEqual(got.Upstreams, want) {
+		t.Errorf("Load() Upstreams = %+v, want %+v", got.Upstreams, want)
+	}
+
+	if len(got.Replicas) != 1 || got.Replicas[0].Host != "replica.local" || got.Replicas[0].Port != 80 {
+		t.Errorf("Load() Replicas = %+v, want one replica.local:80", got.Replicas)
+	}
+
+	if got.Ignored != nil {
+		t.Errorf("Load() Ignored = %+v, want nil", got.Ignored)
+	}
+
+	provenance := loader.Provenance()
+	if origin := provenance["upstreamsConfig.Upstreams[1].Port"]; origin.Key != "UPSTREAM_1_PORT" {
+		t.Errorf("Provenance() Upstreams[1].Port = %+v, want UPSTREAM_1_PORT", origin)
+	}
+
+	if origin := provenance["upstreamsConfig.Upstreams[0].Backups[0].Host"]; origin.Key != "UPSTREAM_0_BACKUP_0_HOST" {
+		t.Errorf("Provenance() Upstreams[0].Backups[0].Host = %+v, want UPSTREAM_0_BACKUP_0_HOST", origin)
+	}
+
+	var empty upstreamsConfig
+	if err := LoadFrom(&empty, MapSource{}); err != nil || empty.Upstreams != nil {
+		t.Errorf("LoadFrom() Upstreams = %+v, error = %v, want nil", empty.Upstreams, err)
+	}
+}
+
+func TestLoadStructSlicesErrors(t *testing.T) {
+	err := LoadFrom(&upstreamsConfig{}, MapSource{"UPSTREAM_0_PORT": "8080"})
+	if !errors.Is(err, NewValidationFailedError("Host", "string", "required")) {
+		t.Fatalf("LoadFrom() error = %v, want the required Host", err)
+	}
+
+	var validation *ValidationFailedError
+	if errors.As(err, &validation); validation.Env != "UPSTREAM_0_HOST" || validation.Path != "upstreamsConfig.Upstreams[0].Host" {
+		t.Errorf("ValidationFailedError = %+v, want UPSTREAM_0_HOST at upstreamsConfig.Upstreams[0].Host", validation)
+	}
+
+	err = LoadFrom(&upstreamsConfig{}, MapSource{"UPSTREAM_0_HOST": "a", "UPSTREAM_0_WEIGHT": "-1"})
+	if err == nil || !strings.Contains(err.Error(), "Weight") {
+		t.Errorf("LoadFrom() error = %v, want the element validation error", err)
+	}
+
+	loader := Loader{CollectErrors: true, Sources: []Source{MapSource{
+		"UPSTREAM_0_HOST": "a",
+		"UPSTREAM_0_PORT": "port",
+		"UPSTREAM_1_PORT": "70000",
+	}}}
+	err = loader.Load(&upstreamsConfig{})
+
+	// the missing Host of the second element is reported once, not by the validator again
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("Load() error = %v, want 3 errors", err)
+	}
+
+	for i, path := range []string{"upstreamsConfig.Upstreams[0].Port", "upstreamsConfig.Upstreams[1].Host", "upstreamsConfig.Upstreams[1].Port"} {
+		if !strings.HasPrefix(errs[i].Error(), path+" ") {
+			t.Errorf("Errors[%d] = %v, want it to name %s", i, errs[i], path)
+		}
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		t.Errorf("Load() error = %v, want no validator errors", err)
+	}
+}
+
+type tenant struct {
+	DSN     string   `env:"DSN" validate:"required"`
+	Pool    int      `env:"DB_POOL" default:"4"`
+	Host    string   `env:"HOST"`
+	DBHost  string   `env:"DB_HOST"`
+	Servers []backup `env:"SERVER"`
+}
+
+type tenantsConfig struct {
+	Tenants map[string]tenant  `env:"TENANT"`
+	Shards  map[string]*tenant `env:"SHARD"`
+}
+
+func TestLoadStructMaps(t *testing.T) {
+	loader := Loader{Sources: []Source{
+		SourceFunc(func(key string) (string, bool) {
+			// the keys of a source that cannot be listed are not discovered, yet they are looked up
+			return "postgres://hidden", key == "TENANT_HIDDEN_DSN" || key == "TENANT_GLOBEX_DSN"
+		}),
+		MapSource{
+			"TENANT_ACME_DSN":            "postgres://acme",
+			"TENANT_ACME_DB_HOST":        "db.acme",
+			"TENANT_ACME_SERVER_0_HOST":  "s0.acme",
+			"TENANT_GLOBEX_CORP_DSN":     "postgres://globex",
+			"TENANT_GLOBEX_CORP_DB_POOL": "16",
+			"TENANT_GLOBEX_DB_HOST":      "db.globex",
+			"TENANT_UNKNOWN":             "no field name",
+			"SHARD_EU_HOST":              "eu.local",
+			"SHARD_EU_DSN":               "postgres://eu",
+		},
+	}}
+
+	var got tenantsConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	want := map[string]tenant{
+		"acme":        {DSN: "postgres://acme", Pool: 4, DBHost: "db.acme", Servers: []backup{{Host: "s0.acme"}}},
+		"globex_corp": {DSN: "postgres://globex", Pool: 16},
+		"globex":      {DSN: "postgres://hidden", Pool: 4, DBHost: "db.globex"},
+	}
+	if !reflect.DeepEqual(got.Tenants, want) {
+		t.Errorf("Load() Tenants = %+v, want %+v", got.Tenants, want)
+	}
+
+	if len(got.Shards) != 1 || got.Shards["eu"] == nil || got.Shards["eu"].Host != "eu.local" {
+		t.Errorf("Load() Shards = %+v, want the eu shard", got.Shards)
+	}
+
+	if origin := loader.Provenance()["tenantsConfig.Tenants[globex_corp].Pool"]; origin.Key != "TENANT_GLOBEX_CORP_DB_POOL" {
+		t.Errorf("Provenance() Tenants[globex_corp].Pool = %+v, want TENANT_GLOBEX_CORP_DB_POOL", origin)
+	}
+
+	var empty tenantsConfig
+	if err := LoadFrom(&empty, MapSource{}); err != nil || empty.Tenants != nil {
+		t.Errorf("LoadFrom() Tenants = %+v, error = %v, want nil", empty.Tenants, err)
+	}
+}
+
+func TestLoadStructMapsErrors(t *testing.T) {
+	err := LoadFrom(&tenantsConfig{}, MapSource{"TENANT_ACME_HOST": "acme.local"})
+
+	var validation *ValidationFailedError
+	if !errors.As(err, &validation) || validation.Env != "TENANT_ACME_DSN" || validation.Path != "tenantsConfig.Tenants[acme].DSN" {
+		t.Errorf("LoadFrom() error = %v, want the required TENANT_ACME_DSN", err)
+	}
+
+	err = LoadFrom(&tenantsConfig{}, MapSource{"TENANT_ACME_DSN": "dsn", "TENANT_ACME_DB_POOL": "many"})
+
+	var incorrect *IncorrectFieldValueError
+	if !errors.As(err, &incorrect) || incorrect.Path != "tenantsConfig.Tenants[acme].Pool" {
+		t.Errorf("LoadFrom() error = %v, want the incorrect Pool of acme", err)
+	}
+
+	var broken struct {
+		Tenants map[int]tenant `env:"TENANT"`
+	}
+	if err = LoadFrom(&broken, MapSource{"TENANT_1_DSN": "dsn"}); !errors.Is(err, NewUnsupportedFieldError("TENANT")) {
+		t.Errorf("LoadFrom() error = %v, want the unsupported map key", err)
+	}
+}
+
+type database struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port uint16 `env:"PORT" validate:"required"`
+	Pool *pool  `prefix:"POOL_"`
+}
+
+type pool struct {
+	Size int `env:"SIZE"`
+}
+
+type prefixedConfig struct {
+	Primary  database   `prefix:"PRIMARY_"`
+	Replica  *database  `envPrefix:"REPLICA_"`
+	Shards   []database `env:"SHARD"`
+	Unprefix database
+}
+
+func TestLoadPrefixes(t *testing.T) {
+	loader := Loader{Prefix: "APP_", Sources: []Source{MapSource{
+		"APP_PRIMARY_HOST":      "primary.local",
+		"APP_PRIMARY_PORT":      "5432",
+		"APP_PRIMARY_POOL_SIZE": "10",
+		"APP_REPLICA_PORT":      "5433",
+		"APP_REPLICA_POOL_SIZE": "5",
+		"APP_SHARD_0_PORT":      "6000",
+		"APP_SHARD_0_POOL_SIZE": "2",
+		"APP_PORT":              "7000",
+		"PRIMARY_PORT":          "1",
+	}}}
+
+	var got prefixedConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	want := prefixedConfig{
+		Primary:  database{Host: "primary.local", Port: 5432, Pool: &pool{Size: 10}},
+		Replica:  &database{Host: "localhost", Port: 5433, Pool: &pool{Size: 5}},
+		Shards:   []database{{Host: "localhost", Port: 6000, Pool: &pool{Size: 2}}},
+		Unprefix: database{Host: "localhost", Port: 7000, Pool: &pool{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() got = %+v, want %+v", got, want)
+	}
+
+	if origin := loader.Provenance()["prefixedConfig.Replica.Pool.Size"]; origin.Key != "APP_REPLICA_POOL_SIZE" {
+		t.Errorf("Provenance() Replica.Pool.Size = %+v, want APP_REPLICA_POOL_SIZE", origin)
+	}
+
+	err := LoadFrom(&prefixedConfig{}, MapSource{"PRIMARY_PORT": "1", "PORT": "1"})
+
+	var validation *ValidationFailedError
+	if !errors.As(err, &validation) || validation.Env != "REPLICA_PORT" {
+		t.Errorf("LoadFrom() error = %v, want the required REPLICA_PORT", err)
+	}
+}
+
+type lookalikeConfig struct {
+	Endpoint lookalikeurl.URL
+	Address  lookalikenet.IP        `env:"ADDRESS"`
+	Timeout  lookaliketime.Duration `env:"TIMEOUT"`
+}
+
+func TestLoadLookalikeTypes(t *testing.T) {
+	var got lookalikeConfig
+	if err := LoadFrom(&got, MapSource{"HOST": "example.com", "ADDRESS": "not an IP", "TIMEOUT": "5"}); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Endpoint.Host != "example.com" || string(got.Address) != "not an IP" || got.Timeout != 5 {
+		t.Errorf("LoadFrom() got = %+v, want the user types loaded as a struct, bytes and an integer", got)
+	}
+}
+
+func TestLoadInvalidBaseTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		load  func(source MapSource) error
+	}{
+		{name: "not a number", value: "abc", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Mask uint32 `env:"MASK" base:"abc"`
+			}{}, source)
+		}},
+		{name: "trailing space", value: "16 ", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Mask uint32 `env:"MASK" base:"16 "`
+			}{}, source)
+		}},
+		{name: "out of range", value: "37", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Mask int `env:"MASK" base:"37"`
+			}{}, source)
+		}},
+		{name: "one", value: "1", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Mask int `env:"MASK" base:"1"`
+			}{}, source)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, source := range []MapSource{{"MASK": "0x1F"}, {}} {
+				err := tt.load(source)
+
+				var target *InvalidTagError
+				if !errors.As(err, &target) || !errors.Is(err, NewInvalidTagError(base, tt.value)) {
+					t.Fatalf("LoadFrom(%v) error = %v, want *InvalidTagError", source, err)
+				}
+
+				if target.Tag != "base" || target.Value != tt.value || !strings.HasSuffix(target.Path, ".Mask") {
+					t.Errorf("InvalidTagError = %+v", target)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadEmptySeparatorTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		load func(source MapSource) error
+	}{
+		{name: "sep", tag: "sep", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Items []string `env:"ITEMS" sep:""`
+			}{}, source)
+		}},
+		{name: "separator", tag: "separator", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Items []string `env:"ITEMS" separator:""`
+			}{}, source)
+		}},
+		{name: "kvsep", tag: "kvsep", load: func(source MapSource) error {
+			return LoadFrom(&struct {
+				Items map[string]string `env:"ITEMS" kvsep:""`
+			}{}, source)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.load(MapSource{"ITEMS": "abc"})
+
+			var target *InvalidTagError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *InvalidTagError", err)
+			}
+
+			if target.Tag != tt.tag || target.Value != "" || !strings.HasSuffix(target.Path, ".Items") {
+				t.Errorf("InvalidTagError = %+v", target)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package settings
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
 
 var (
 	ErrTheModelHasEmptyStruct = errors.New("an input struct has no fields")
@@ -8,57 +12,176 @@ var (
 	ErrNotAddressable         = errors.New("the main struct must be pointed out via pointer")
 	ErrNotAddressableField    = errors.New("the value is not addressable or main struct is not indicated via pointer")
 	ErrInternalFailure        = errors.New("an internal package error")
+	ErrUnsupportedFileFormat  = errors.New("the config file format is not supported")
+	ErrNoKeyValueSeparator    = errors.New("the map element has no key-value separator")
+	ErrRedactedCause          = errors.New("the secret value cannot be converted")
 )
 
-type unsupportedFieldError string
+// UnsupportedFieldError — the field type cannot be loaded.
+type UnsupportedFieldError struct {
+	// Env — the env variable name.
+	Env string
+	// Path — the Go field path, e.g. Settings.DB.Port.
+	Path string
+	// Type — the field type.
+	Type string
+}
 
-func (err unsupportedFieldError) Error() string {
-	return "environment variable '" + string(err) + "' has been found but the field type is unsupported"
+func (err *UnsupportedFieldError) Error() string {
+	return "environment variable '" + err.Env + "' has been found but the field type is unsupported"
 }
 
-func (err unsupportedFieldError) Is(target error) bool {
-	_, ok := target.(unsupportedFieldError)
+func (err *UnsupportedFieldError) Is(target error) bool {
+	_, ok := target.(*UnsupportedFieldError)
 	return ok
 }
 
-type incorrectFieldValueError string
+// IncorrectFieldValueError — the value cannot be converted to the field type.
+type IncorrectFieldValueError struct {
+	// Env — the env variable name.
+	Env string
+	// Path — the Go field path, e.g. Settings.DB.Port.
+	Path string
+	// Value — the raw value, it is replaced with "***" for the fields tagged with secret:"true".
+	Value string
+	// Type — the field type.
+	Type string
+	// Err — the underlying parse error, may be nil.
+	Err error
+}
 
-func (err incorrectFieldValueError) Error() string {
-	return "environment variable '" + string(err) + "' has been found but has incorrect value"
+func (err *IncorrectFieldValueError) Error() string {
+	return "environment variable '" + err.Env + "' has been found but has incorrect value"
 }
 
-func (err incorrectFieldValueError) Is(target error) bool {
-	_, ok := target.(incorrectFieldValueError)
+func (err *IncorrectFieldValueError) Is(target error) bool {
+	_, ok := target.(*IncorrectFieldValueError)
 	return ok
 }
 
-type validationFailedError struct {
-	Name           string
-	Type           string
+func (err *IncorrectFieldValueError) Unwrap() error {
+	return err.Err
+}
+
+// ValidationFailedError — the field failed the validation rule before it was loaded,
+// e.g. a required variable is absent.
+type ValidationFailedError struct {
+	// Name — the field name.
+	Name string
+	// Type — the field type.
+	Type string
+	// ValidationRule — the rule that failed.
 	ValidationRule string
+	// Env — the env variable name.
+	Env string
+	// Path — the Go field path, e.g. Settings.DB.Port.
+	Path string
 }
 
-func (err *validationFailedError) Error() string {
+func (err *ValidationFailedError) Error() string {
 	return "validation with rule '" + err.ValidationRule + "' failed on the field '" + err.Name + "' of '" + err.Type + "' type"
 }
 
-func (err *validationFailedError) Is(target error) bool {
-	_, ok := target.(*validationFailedError)
+func (err *ValidationFailedError) Is(target error) bool {
+	_, ok := target.(*ValidationFailedError)
+	return ok
+}
+
+// InvalidTagError — the field tag has a value that cannot be used, e.g. base:"hex".
+type InvalidTagError struct {
+	// Path — the Go field path, e.g. Settings.DB.Port.
+	Path string
+	// Tag — the tag name.
+	Tag string
+	// Value — the tag value.
+	Value string
+}
+
+func (err *InvalidTagError) Error() string {
+	return "the tag '" + err.Tag + "' of the field '" + err.Path + "' has the invalid value '" + err.Value + "'"
+}
+
+func (err *InvalidTagError) Is(target error) bool {
+	_, ok := target.(*InvalidTagError)
+	return ok
+}
+
+// Errors — all the errors found by a Loader with CollectErrors enabled.
+// It supports errors.Is and errors.As the same way errors.Join does.
+type Errors []error
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// fieldError — an error of a field with the Go field path and the env variable name.
+type fieldError struct {
+	Path string
+	Env  string
+	Err  error
+}
+
+func (err *fieldError) Error() string {
+	if err.Env == "" {
+		return err.Path + ": " + err.Err.Error()
+	}
+
+	return err.Path + " (" + err.Env + "): " + err.Err.Error()
+}
+
+func (err *fieldError) Unwrap() error {
+	return err.Err
+}
+
+// DotenvSyntaxError — the dotenv file cannot be parsed.
+type DotenvSyntaxError struct {
+	// Line — the line number, starting with 1.
+	Line int
+	// Reason — what is wrong on the line.
+	Reason string
+}
+
+func (err *DotenvSyntaxError) Error() string {
+	return "dotenv syntax error on line " + strconv.Itoa(err.Line) + ": " + err.Reason
+}
+
+func (err *DotenvSyntaxError) Is(target error) bool {
+	_, ok := target.(*DotenvSyntaxError)
 	return ok
 }
 
 func NewUnsupportedFieldError(fieldName string) error {
-	return unsupportedFieldError(fieldName)
+	return &UnsupportedFieldError{Env: fieldName}
 }
 
 func NewIncorrectFieldValueError(fieldName string) error {
-	return incorrectFieldValueError(fieldName)
+	return &IncorrectFieldValueError{Env: fieldName}
 }
 
 func NewValidationFailedError(name, fieldType, validationRule string) error {
-	return &validationFailedError{
+	return &ValidationFailedError{
 		Name:           name,
 		Type:           fieldType,
 		ValidationRule: validationRule,
 	}
 }
+
+func NewInvalidTagError(tag, value string) error {
+	return &InvalidTagError{Tag: tag, Value: value}
+}
+
+func NewDotenvSyntaxError(line int, reason string) error {
+	return &DotenvSyntaxError{
+		Line:   line,
+		Reason: reason,
+	}
+}
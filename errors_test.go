@@ -2,7 +2,10 @@ package settings
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestUnsupportedFieldError(t *testing.T) {
@@ -30,9 +33,9 @@ func TestUnsupportedFieldError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := unsupportedFieldError(tt.field)
+			err := &UnsupportedFieldError{Env: tt.field}
 			if err.Error() != tt.expected {
-				t.Errorf("unsupportedFieldError.Error() = %v, want %v", err.Error(), tt.expected)
+				t.Errorf("UnsupportedFieldError.Error() = %v, want %v", err.Error(), tt.expected)
 			}
 		})
 	}
@@ -63,9 +66,9 @@ func TestIncorrectFieldValueError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := incorrectFieldValueError(tt.field)
+			err := &IncorrectFieldValueError{Env: tt.field}
 			if err.Error() != tt.expected {
-				t.Errorf("incorrectFieldValueError.Error() = %v, want %v", err.Error(), tt.expected)
+				t.Errorf("IncorrectFieldValueError.Error() = %v, want %v", err.Error(), tt.expected)
 			}
 		})
 	}
@@ -104,13 +107,13 @@ func TestValidationFailedError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := &validationFailedError{
+			err := &ValidationFailedError{
 				Name:           tt.fieldName,
 				Type:           tt.fieldType,
 				ValidationRule: tt.validationRule,
 			}
 			if err.Error() != tt.expected {
-				t.Errorf("validationFailedError.Error() = %v, want %v", err.Error(), tt.expected)
+				t.Errorf("ValidationFailedError.Error() = %v, want %v", err.Error(), tt.expected)
 			}
 		})
 	}
@@ -125,25 +128,25 @@ func TestUnsupportedFieldErrorIs(t *testing.T) {
 	}{
 		{
 			name:     "same type",
-			err:      unsupportedFieldError("PORT"),
-			target:   unsupportedFieldError("DB"),
+			err:      &UnsupportedFieldError{Env: "PORT"},
+			target:   &UnsupportedFieldError{Env: "DB"},
 			expected: true,
 		},
 		{
 			name:     "different type",
-			err:      unsupportedFieldError("PORT"),
-			target:   incorrectFieldValueError("PORT"),
+			err:      &UnsupportedFieldError{Env: "PORT"},
+			target:   &IncorrectFieldValueError{Env: "PORT"},
 			expected: false,
 		},
 		{
 			name:     "nil target",
-			err:      unsupportedFieldError("PORT"),
+			err:      &UnsupportedFieldError{Env: "PORT"},
 			target:   nil,
 			expected: false,
 		},
 		{
 			name:     "standard error",
-			err:      unsupportedFieldError("PORT"),
+			err:      &UnsupportedFieldError{Env: "PORT"},
 			target:   errors.New("some error"),
 			expected: false,
 		},
@@ -168,25 +171,25 @@ func TestIncorrectFieldValueErrorIs(t *testing.T) {
 	}{
 		{
 			name:     "same type",
-			err:      incorrectFieldValueError("PORT"),
-			target:   incorrectFieldValueError("DB"),
+			err:      &IncorrectFieldValueError{Env: "PORT"},
+			target:   &IncorrectFieldValueError{Env: "DB"},
 			expected: true,
 		},
 		{
 			name:     "different type",
-			err:      incorrectFieldValueError("PORT"),
-			target:   unsupportedFieldError("PORT"),
+			err:      &IncorrectFieldValueError{Env: "PORT"},
+			target:   &UnsupportedFieldError{Env: "PORT"},
 			expected: false,
 		},
 		{
 			name:     "nil target",
-			err:      incorrectFieldValueError("PORT"),
+			err:      &IncorrectFieldValueError{Env: "PORT"},
 			target:   nil,
 			expected: false,
 		},
 		{
 			name:     "standard error",
-			err:      incorrectFieldValueError("PORT"),
+			err:      &IncorrectFieldValueError{Env: "PORT"},
 			target:   errors.New("some error"),
 			expected: false,
 		},
@@ -211,12 +214,12 @@ func TestValidationFailedErrorIs(t *testing.T) {
 	}{
 		{
 			name: "same type",
-			err: &validationFailedError{
+			err: &ValidationFailedError{
 				Name:           "Port",
 				Type:           "int",
 				ValidationRule: "required",
 			},
-			target: &validationFailedError{
+			target: &ValidationFailedError{
 				Name:           "DB",
 				Type:           "string",
 				ValidationRule: "min=5",
@@ -225,17 +228,17 @@ func TestValidationFailedErrorIs(t *testing.T) {
 		},
 		{
 			name: "different type",
-			err: &validationFailedError{
+			err: &ValidationFailedError{
 				Name:           "Port",
 				Type:           "int",
 				ValidationRule: "required",
 			},
-			target:   unsupportedFieldError("Port"),
+			target:   &UnsupportedFieldError{Env: "Port"},
 			expected: false,
 		},
 		{
 			name: "nil target",
-			err: &validationFailedError{
+			err: &ValidationFailedError{
 				Name:           "Port",
 				Type:           "int",
 				ValidationRule: "required",
@@ -245,7 +248,7 @@ func TestValidationFailedErrorIs(t *testing.T) {
 		},
 		{
 			name: "standard error",
-			err: &validationFailedError{
+			err: &ValidationFailedError{
 				Name:           "Port",
 				Type:           "int",
 				ValidationRule: "required",
@@ -296,8 +299,8 @@ func TestNewUnsupportedFieldError(t *testing.T) {
 			}
 
 			// Test that it returns the correct type
-			if _, ok := err.(unsupportedFieldError); !ok {
-				t.Errorf("NewUnsupportedFieldError(%v) should return unsupportedFieldError type", tt.field)
+			if _, ok := err.(*UnsupportedFieldError); !ok {
+				t.Errorf("NewUnsupportedFieldError(%v) should return *UnsupportedFieldError type", tt.field)
 			}
 		})
 	}
@@ -334,8 +337,8 @@ func TestNewIncorrectFieldValueError(t *testing.T) {
 			}
 
 			// Test that it returns the correct type
-			if _, ok := err.(incorrectFieldValueError); !ok {
-				t.Errorf("NewIncorrectFieldValueError(%v) should return incorrectFieldValueError type", tt.field)
+			if _, ok := err.(*IncorrectFieldValueError); !ok {
+				t.Errorf("NewIncorrectFieldValueError(%v) should return *IncorrectFieldValueError type", tt.field)
 			}
 		})
 	}
@@ -381,8 +384,8 @@ func TestNewValidationFailedError(t *testing.T) {
 			}
 
 			// Test that it returns the correct type
-			if _, ok := err.(*validationFailedError); !ok {
-				t.Errorf("NewValidationFailedError(%v, %v, %v) should return *validationFailedError type",
+			if _, ok := err.(*ValidationFailedError); !ok {
+				t.Errorf("NewValidationFailedError(%v, %v, %v) should return *ValidationFailedError type",
 					tt.fieldName, tt.fieldType, tt.validationRule)
 			}
 		})
@@ -390,9 +393,9 @@ func TestNewValidationFailedError(t *testing.T) {
 }
 
 func TestErrorTypes(t *testing.T) {
-	var _ error = unsupportedFieldError("test")
-	var _ error = incorrectFieldValueError("test")
-	var _ error = &validationFailedError{}
+	var _ error = &UnsupportedFieldError{Env: "test"}
+	var _ error = &IncorrectFieldValueError{Env: "test"}
+	var _ error = &ValidationFailedError{}
 }
 
 func TestErrorWrapping(t *testing.T) {
@@ -406,11 +409,237 @@ func TestErrorWrapping(t *testing.T) {
 	}
 
 	// Test error unwrapping
-	var targetErr unsupportedFieldError
-	if !errors.As(originalErr, &targetErr) {
-		t.Error("Should be able to unwrap to unsupportedFieldError")
+	var targetErr *UnsupportedFieldError
+	if !errors.As(wrappedErr, &targetErr) {
+		t.Error("Should be able to unwrap to *UnsupportedFieldError")
 	}
-	if targetErr != "PORT" {
-		t.Errorf("Unwrapped error should have field 'PORT', got %v", targetErr)
+	if targetErr.Env != "PORT" {
+		t.Errorf("Unwrapped error should have field 'PORT', got %v", targetErr.Env)
+	}
+}
+
+type structuredErrorsConfig struct {
+	DB structuredErrorsDB
+}
+
+type structuredErrorsDB struct {
+	Port     uint8    `env:"DB_PORT"`
+	Password int      `env:"DB_PASSWORD" secret:"true"`
+	Name     string   `env:"DB_NAME"     validate:"required"`
+	Hosts    chan int `env:"DB_HOSTS"`
+}
+
+func TestStructuredErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "incorrect value",
+			source: MapSource{"DB_PORT": "300"},
+			check: func(t *testing.T, err error) {
+				var target *IncorrectFieldValueError
+				if !errors.As(err, &target) {
+					t.Fatalf("errors.As() failed for %v", err)
+				}
+
+				if target.Env != "DB_PORT" || target.Path != "structuredErrorsConfig.DB.Port" ||
+					target.Value != "300" || target.Type != "uint8" {
+					t.Errorf("IncorrectFieldValueError = %+v", target)
+				}
+
+				if !errors.Is(err, strconv.ErrRange) {
+					t.Errorf("IncorrectFieldValueError must wrap strconv.ErrRange, got %v", target.Err)
+				}
+			},
+		},
+		{
+			name:   "parse error cause",
+			source: MapSource{"DB_PORT": "port"},
+			check: func(t *testing.T, err error) {
+				var numError *strconv.NumError
+				if !errors.As(err, &numError) || !errors.Is(err, strconv.ErrSyntax) {
+					t.Errorf("IncorrectFieldValueError must wrap *strconv.NumError, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "secret value",
+			source: MapSource{"DB_PASSWORD": "hunter2"},
+			check: func(t *testing.T, err error) {
+				var target *IncorrectFieldValueError
+				if !errors.As(err, &target) {
+					t.Fatalf("errors.As() failed for %v", err)
+				}
+
+				if target.Value != "***" {
+					t.Errorf("IncorrectFieldValueError.Value = %q, want redacted", target.Value)
+				}
+			},
+		},
+		{
+			name:   "required",
+			source: MapSource{},
+			check: func(t *testing.T, err error) {
+				var target *ValidationFailedError
+				if !errors.As(err, &target) {
+					t.Fatalf("errors.As() failed for %v", err)
+				}
+
+				if target.Env != "DB_NAME" || target.Path != "structuredErrorsConfig.DB.Name" ||
+					target.Name != "Name" || target.Type != "string" || target.ValidationRule != "required" {
+					t.Errorf("ValidationFailedError = %+v", target)
+				}
+			},
+		},
+		{
+			name:   "unsupported",
+			source: MapSource{"DB_NAME": "db", "DB_HOSTS": "1,2"},
+			check: func(t *testing.T, err error) {
+				var target *UnsupportedFieldError
+				if !errors.As(err, &target) {
+					t.Fatalf("errors.As() failed for %v", err)
+				}
+
+				if target.Env != "DB_HOSTS" || target.Path != "structuredErrorsConfig.DB.Hosts" || target.Type != "chan int" {
+					t.Errorf("UnsupportedFieldError = %+v", target)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&structuredErrorsConfig{}, tt.source)
+			if err == nil {
+				t.Fatal("LoadFrom() expected error but got nil")
+			}
+
+			tt.check(t, err)
+		})
+	}
+}
+
+type conversionMatrixConfig struct {
+	Int       int           `env:"INT"`
+	Int8      int8          `env:"INT8"`
+	Int16     int16         `env:"INT16"`
+	Int32     int32         `env:"INT32"`
+	Int64     int64         `env:"INT64"`
+	Uint      uint          `env:"UINT"`
+	Uint8     uint8         `env:"UINT8"`
+	Uint16    uint16        `env:"UINT16"`
+	Uint32    uint32        `env:"UINT32"`
+	Uint64    uint64        `env:"UINT64"`
+	Float64   float64       `env:"FLOAT64"`
+	Duration  time.Duration `env:"DURATION"`
+	Bool      bool          `env:"BOOL"`
+	Float32   float32       `env:"FLOAT32"`
+	Complex64 complex64     `env:"COMPLEX64"`
+	Complex   complex128    `env:"COMPLEX128"`
+}
+
+func TestConversionErrorsMatrix(t *testing.T) {
+	tests := []struct {
+		env       string
+		value     string
+		fieldType string
+		cause     error
+	}{
+		{env: "INT", value: "one", fieldType: "int", cause: strconv.ErrSyntax},
+		{env: "INT", value: "9223372036854775808", fieldType: "int", cause: strconv.ErrRange},
+		{env: "INT8", value: "1.5", fieldType: "int8", cause: strconv.ErrSyntax},
+		{env: "INT8", value: "128", fieldType: "int8", cause: strconv.ErrRange},
+		{env: "INT16", value: "-32769", fieldType: "int16", cause: strconv.ErrRange},
+		{env: "INT32", value: "", fieldType: "int32", cause: strconv.ErrSyntax},
+		{env: "INT32", value: "2147483648", fieldType: "int32", cause: strconv.ErrRange},
+		{env: "INT64", value: "0x10", fieldType: "int64", cause: strconv.ErrSyntax},
+		{env: "UINT", value: "-1", fieldType: "uint", cause: strconv.ErrSyntax},
+		{env: "UINT", value: "18446744073709551616", fieldType: "uint", cause: strconv.ErrRange},
+		{env: "UINT8", value: "256", fieldType: "uint8", cause: strconv.ErrRange},
+		{env: "UINT16", value: "65536", fieldType: "uint16", cause: strconv.ErrRange},
+		{env: "UINT32", value: "4294967296", fieldType: "uint32", cause: strconv.ErrRange},
+		{env: "UINT64", value: "many", fieldType: "uint64", cause: strconv.ErrSyntax},
+		{env: "FLOAT64", value: "pi", fieldType: "float64", cause: strconv.ErrSyntax},
+		{env: "FLOAT64", value: "1e309", fieldType: "float64", cause: strconv.ErrRange},
+		{env: "DURATION", value: "5", fieldType: "time.Duration"},
+		{env: "DURATION", value: "soon", fieldType: "time.Duration"},
+		{env: "BOOL", value: "ture", fieldType: "bool", cause: strconv.ErrSyntax},
+		{env: "BOOL", value: "", fieldType: "bool", cause: strconv.ErrSyntax},
+		{env: "FLOAT32", value: "3.5e38", fieldType: "float32", cause: strconv.ErrRange},
+		{env: "FLOAT32", value: "1,5", fieldType: "float32", cause: strconv.ErrSyntax},
+		{env: "COMPLEX64", value: "1+2j", fieldType: "complex64", cause: strconv.ErrSyntax},
+		{env: "COMPLEX64", value: "1e39+1i", fieldType: "complex64", cause: strconv.ErrRange},
+		{env: "COMPLEX128", value: "i+1", fieldType: "complex128", cause: strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			err := LoadFrom(&conversionMatrixConfig{}, MapSource{tt.env: tt.value})
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			if target.Env != tt.env || target.Value != tt.value || target.Type != tt.fieldType {
+				t.Errorf("IncorrectFieldValueError = %+v", target)
+			}
+
+			if target.Err == nil {
+				t.Fatal("IncorrectFieldValueError must wrap the parse error")
+			}
+
+			if tt.cause != nil && !errors.Is(err, tt.cause) {
+				t.Errorf("LoadFrom() error cause = %v, want %v", target.Err, tt.cause)
+			}
+		})
+	}
+}
+
+type secretsConfig struct {
+	PIN     int               `env:"PIN"     secret:"true"`
+	Timeout time.Duration     `env:"TIMEOUT" secret:"true"`
+	Tokens  map[string]uint8  `env:"TOKENS"  secret:"true"`
+	Since   time.Time         `env:"SINCE"   secret:"true"`
+	Hosts   map[string]string `env:"HOSTS"   secret:"true"`
+}
+
+func TestSecretCausesAreRedacted(t *testing.T) {
+	tests := []struct {
+		name   string
+		source MapSource
+		secret string
+		cause  error
+	}{
+		{name: "int", source: MapSource{"PIN": "hunter2"}, secret: "hunter2", cause: strconv.ErrSyntax},
+		{name: "int range", source: MapSource{"PIN": "99999999999999999999"}, secret: "99999999999999999999", cause: strconv.ErrRange},
+		{name: "duration", source: MapSource{"TIMEOUT": "s3cr3t"}, secret: "s3cr3t", cause: ErrRedactedCause},
+		{name: "map value", source: MapSource{"TOKENS": "api:s3cr3t"}, secret: "s3cr3t", cause: strconv.ErrSyntax},
+		{name: "map key", source: MapSource{"TOKENS": "s3cr3t:300"}, secret: "s3cr3t", cause: strconv.ErrRange},
+		{name: "map pair", source: MapSource{"HOSTS": "s3cr3t"}, secret: "s3cr3t", cause: ErrNoKeyValueSeparator},
+		{name: "time", source: MapSource{"SINCE": "s3cr3t"}, secret: "s3cr3t", cause: ErrRedactedCause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&secretsConfig{}, tt.source)
+
+			var target *IncorrectFieldValueError
+			if !errors.As(err, &target) {
+				t.Fatalf("LoadFrom() error = %v, want *IncorrectFieldValueError", err)
+			}
+
+			for _, message := range []string{err.Error(), errors.Unwrap(err).Error(), target.Path, target.Value} {
+				if strings.Contains(message, tt.secret) {
+					t.Errorf("%q contains the secret %q", message, tt.secret)
+				}
+			}
+
+			if !errors.Is(err, tt.cause) {
+				t.Errorf("LoadFrom() error = %v, want the cause %v", target.Err, tt.cause)
+			}
+		})
 	}
 }
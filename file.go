@@ -0,0 +1,226 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// File reads the config file and returns it as a source. The format is chosen by the file
+// extension: .json, .yaml, .yml, .toml or .env. The path is used as the layer name in
+// the Provenance.
+func File(path string) (Source, error) {
+	var parse func(io.Reader) (Source, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		parse = parseWith(ParseJSON)
+	case ".yaml", ".yml":
+		parse = parseWith(ParseYAML)
+	case ".toml":
+		parse = parseWith(ParseTOML)
+	case ".env":
+		parse = parseWith(ParseDotenv)
+	default:
+		return nil, ErrUnsupportedFileFormat
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values, err := parse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return Named(path, values), nil
+}
+
+// parseWith adapts a parse function to return a Source.
+func parseWith[T Source](parse func(io.Reader) (T, error)) func(io.Reader) (Source, error) {
+	return func(r io.Reader) (Source, error) {
+		return parse(r)
+	}
+}
+
+// ConfigSource — the values of a config file. Lookup returns the scalars and the lists joined
+// with a comma, LookupList returns the lists element by element, so the list fields keep the
+// element boundaries whatever the field separator is. LookupMapping returns the objects of
+// scalars for the map fields.
+type ConfigSource struct {
+	// Values — the scalars and the joined lists by the flat keys, e.g. DB_PORT.
+	Values MapSource
+	// Lists — the elements of the lists of scalars by the flat keys.
+	Lists map[string][]string
+	// Mappings — the objects of scalars by the flat keys, the object keys keep their case.
+	Mappings map[string]map[string]string
+}
+
+// Lookup returns the value stored under the key.
+func (source *ConfigSource) Lookup(key string) (string, bool) {
+	return source.Values.Lookup(key)
+}
+
+// LookupList returns the elements of the list stored under the key.
+func (source *ConfigSource) LookupList(key string) ([]string, bool) {
+	list, ok := source.Lists[key]
+	return list, ok
+}
+
+// LookupMapping returns the keys and the values of the object stored under the key.
+func (source *ConfigSource) LookupMapping(key string) (map[string]string, bool) {
+	mapping, ok := source.Mappings[key]
+	return mapping, ok
+}
+
+// Keys returns the keys of the values.
+func (source *ConfigSource) Keys() []string {
+	return source.Values.Keys()
+}
+
+// String returns the layer name of the source.
+func (source *ConfigSource) String() string {
+	return "config"
+}
+
+// ParseJSON parses a JSON object into keys that match the env tags.
+// See flattenConfig for the key naming rules.
+func ParseJSON(r io.Reader) (*ConfigSource, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return flattenConfig(document), nil
+}
+
+// ParseYAML parses a YAML mapping into keys that match the env tags.
+// See flattenConfig for the key naming rules.
+func ParseYAML(r io.Reader) (*ConfigSource, error) {
+	var document map[string]any
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return flattenConfig(document), nil
+}
+
+// ParseTOML parses a TOML document into keys that match the env tags.
+// See flattenConfig for the key naming rules.
+func ParseTOML(r io.Reader) (*ConfigSource, error) {
+	var document map[string]any
+	if _, err := toml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return flattenConfig(document), nil
+}
+
+// flattenConfig turns a decoded config file into keys that match the env tags. The keys are
+// upper-cased and the keys of nested objects are joined with '_', so
+//
+//	db:
+//	  port: 5432
+//	hosts: [a, b]
+//	upstreams:
+//	  - host: a
+//
+// gives DB_PORT=5432, HOSTS=a,b and UPSTREAMS_0_HOST=a, the elements of HOSTS are kept as a
+// list and DB is kept as a mapping as well. The values are formatted as strings to be converted by Load exactly as the
+// environment variables are.
+func flattenConfig(document map[string]any) *ConfigSource {
+	source := &ConfigSource{
+		Values:   make(MapSource),
+		Lists:    make(map[string][]string),
+		Mappings: make(map[string]map[string]string),
+	}
+	for key, value := range document {
+		source.flatten(strings.ToUpper(key), value)
+	}
+
+	return source
+}
+
+// flatten adds the value to the source under the flat key.
+func (source *ConfigSource) flatten(key string, value any) {
+	switch typed := value.(type) {
+	case nil:
+		// null is treated as an absent value
+	case map[string]any:
+		// an object of scalars may be loaded to a map field as well
+		mapping := make(map[string]string, len(typed))
+		for nestedKey, nestedValue := range typed {
+			source.flatten(key+keySeparator+strings.ToUpper(nestedKey), nestedValue)
+			if scalar, ok := formatScalar(nestedValue); ok && mapping != nil {
+				mapping[nestedKey] = scalar
+			} else if nestedValue != nil {
+				mapping = nil
+			}
+		}
+		if len(mapping) != 0 {
+			source.Mappings[key] = mapping
+		}
+	case []map[string]any:
+		for i, element := range typed {
+			source.flatten(key+keySeparator+strconv.Itoa(i), element)
+		}
+	case []any:
+		elements := make([]string, 0, len(typed))
+		for i, element := range typed {
+			scalar, ok := formatScalar(element)
+			if !ok {
+				source.flatten(key+keySeparator+strconv.Itoa(i), element)
+				continue
+			}
+			elements = append(elements, scalar)
+		}
+		if len(elements) == len(typed) {
+			source.Values[key] = strings.Join(elements, defaultSeparator)
+			source.Lists[key] = elements
+		}
+	default:
+		if scalar, ok := formatScalar(value); ok {
+			source.Values[key] = scalar
+		}
+	}
+}
+
+// formatScalar formats a scalar value of a config file, ok is false for the objects and lists.
+func formatScalar(value any) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case bool:
+		return strconv.FormatBool(typed), true
+	case json.Number:
+		return typed.String(), true
+	case int:
+		return strconv.Itoa(typed), true
+	case int64:
+		return strconv.FormatInt(typed, 10), true
+	case uint64:
+		return strconv.FormatUint(typed, 10), true
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), true
+	case time.Time:
+		return typed.Format(time.RFC3339Nano), true
+	case fmt.Stringer:
+		// TOML local dates and times
+		return typed.String(), true
+	default:
+		return "", false
+	}
+}
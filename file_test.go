@@ -0,0 +1,281 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fileConfig struct {
+	Port    uint16        `env:"PORT"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Hosts   []string      `env:"HOSTS"`
+	Debug   bool          `env:"DEBUG"`
+	Ratio   float64       `env:"RATIO"`
+	DB      fileDBConfig
+}
+
+type fileDBConfig struct {
+	URL  string `env:"DB_URL"  validate:"required"`
+	Pool int8   `env:"DB_POOL"`
+}
+
+func TestFile(t *testing.T) {
+	want := fileConfig{
+		Port:    8080,
+		Timeout: 1500 * time.Millisecond,
+		Hosts:   []string{"a", "b"},
+		Debug:   true,
+		Ratio:   0.5,
+		DB:      fileDBConfig{URL: "postgres://db", Pool: 10},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr error
+	}{
+		{
+			name: "json",
+			file: "config.json",
+			content: `{"port": 8080, "timeout": "1.5s", "hosts": ["a", "b"], "debug": true, "ratio": 0.5,
+				"db": {"url": "postgres://db", "pool": 10}}`,
+		},
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: "port: 8080\ntimeout: 1.5s\nhosts:\n  - a\n  - b\ndebug: true\nratio: 0.5\n" +
+				"db:\n  url: postgres://db\n  pool: 10\n",
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: "port = 8080\ntimeout = \"1.5s\"\nhosts = [\"a\", \"b\"]\ndebug = true\nratio = 0.5\n" +
+				"[db]\nurl = \"postgres://db\"\npool = 10\n",
+		},
+		{
+			name:    "dotenv",
+			file:    "config.env",
+			content: "PORT=8080\nTIMEOUT=1.5s\nHOSTS=a,b\nDEBUG=true\nRATIO=0.5\nDB_URL=postgres://db\nDB_POOL=10\n",
+		},
+		{
+			name:    "range check",
+			file:    "config.yml",
+			content: "db:\n  url: postgres://db\n  pool: 300\n",
+			wantErr: NewIncorrectFieldValueError("DB_POOL"),
+		},
+		{
+			name:    "unknown format",
+			file:    "config.ini",
+			content: "port=8080",
+			wantErr: ErrUnsupportedFileFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			source, err := File(path)
+			if err == nil {
+				var got fileConfig
+				err = LoadFrom(&got, source)
+				if err == nil && tt.wantErr == nil {
+					if got.Port != want.Port || got.Timeout != want.Timeout || got.Debug != want.Debug ||
+						got.Ratio != want.Ratio || got.DB != want.DB ||
+						len(got.Hosts) != 2 || got.Hosts[0] != "a" || got.Hosts[1] != "b" {
+						t.Errorf("LoadFrom() got = %+v, want %+v", got, want)
+					}
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileEnvironmentOverride(t *testing.T) {
+	t.Setenv("PORT", "9090")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 8080, "db": {"url": "postgres://db"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := File(path)
+	if err != nil {
+		t.Fatalf("File() unexpected error = %v", err)
+	}
+
+	var got fileConfig
+	if err = LoadFrom(&got, Environment, file); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Port != 9090 || got.DB.URL != "postgres://db" {
+		t.Errorf("LoadFrom() got = %+v", got)
+	}
+}
+
+func TestFlattenConfig(t *testing.T) {
+	source, err := ParseYAML(strings.NewReader("upstreams:\n  - host: a\n    port: 1\n  - host: b\nempty:\n"))
+	if err != nil {
+		t.Fatalf("ParseYAML() unexpected error = %v", err)
+	}
+
+	want := MapSource{"UPSTREAMS_0_HOST": "a", "UPSTREAMS_0_PORT": "1", "UPSTREAMS_1_HOST": "b"}
+	if len(source.Values) != len(want) {
+		t.Errorf("ParseYAML() got = %v, want %v", source.Values, want)
+	}
+	for key, value := range want {
+		if source.Values[key] != value {
+			t.Errorf("ParseYAML()[%s] = %q, want %q", key, source.Values[key], value)
+		}
+	}
+}
+
+type fileListsConfig struct {
+	Hosts  []string         `env:"HOSTS"`
+	Ports  []uint16         `env:"PORTS" sep:";"`
+	Waits  *[]time.Duration `env:"WAITS" sep:" "`
+	Tags   []string         `env:"TAGS"  trim:"true" skipempty:"true"`
+	Single string           `env:"SINGLE"`
+}
+
+func TestFileListsKeepElements(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			file:    "config.yaml",
+			content: "hosts: ['a,b', c]\nports: [1, 2]\nwaits: [1s, 2s]\ntags: [' x ', '']\nsingle: [d, e]\n",
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"hosts": ["a,b", "c"], "ports": [1, 2], "waits": ["1s", "2s"], "tags": [" x ", ""], "single": ["d", "e"]}`,
+		},
+		{
+			name:    "toml",
+			file:    "config.toml",
+			content: "hosts = ['a,b', 'c']\nports = [1, 2]\nwaits = ['1s', '2s']\ntags = [' x ', '']\nsingle = ['d', 'e']\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			source, err := File(path)
+			if err != nil {
+				t.Fatalf("File() unexpected error = %v", err)
+			}
+
+			var got fileListsConfig
+			if err = LoadFrom(&got, source); err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			want := fileListsConfig{
+				Hosts:  []string{"a,b", "c"},
+				Ports:  []uint16{1, 2},
+				Waits:  &[]time.Duration{time.Second, 2 * time.Second},
+				Tags:   []string{"x"},
+				Single: "d,e",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadFrom() got = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+type fileMapsConfig struct {
+	Labels   map[string]string        `env:"LABELS"`
+	Timeouts map[string]time.Duration `env:"TIMEOUTS" sep:";" kvsep:"="`
+	Limits   *map[string]uint8        `env:"LIMITS"`
+	DB       struct {
+		Host string `env:"DB_HOST"`
+	}
+}
+
+func TestFileMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			file:    "config.yaml",
+			content: "labels: {Team: core, 'a:b': 'c,d'}\ntimeouts: {read: 1s}\nlimits: {small: 1}\ndb: {host: db.local}\n",
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"labels": {"Team": "core", "a:b": "c,d"}, "timeouts": {"read": "1s"}, "limits": {"small": 1}, "db": {"host": "db.local"}}`,
+		},
+		{
+			name:    "toml",
+			file:    "config.toml",
+			content: "[labels]\nTeam = 'core'\n'a:b' = 'c,d'\n[timeouts]\nread = '1s'\n[limits]\nsmall = 1\n[db]\nhost = 'db.local'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			source, err := File(path)
+			if err != nil {
+				t.Fatalf("File() unexpected error = %v", err)
+			}
+
+			var got fileMapsConfig
+			if err = LoadFrom(&got, source); err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Labels, map[string]string{"Team": "core", "a:b": "c,d"}) ||
+				!reflect.DeepEqual(got.Timeouts, map[string]time.Duration{"read": time.Second}) ||
+				got.Limits == nil || !reflect.DeepEqual(*got.Limits, map[string]uint8{"small": 1}) ||
+				got.DB.Host != "db.local" {
+				t.Errorf("LoadFrom() got = %+v", got)
+			}
+		})
+	}
+
+	source, err := ParseYAML(strings.NewReader("limits: {small: 300}\nnested: {a: {b: c}}\n"))
+	if err != nil {
+		t.Fatalf("ParseYAML() unexpected error = %v", err)
+	}
+
+	if _, ok := source.LookupMapping("NESTED"); ok {
+		t.Error("LookupMapping() must not return the objects that have nested objects")
+	}
+
+	var incorrect *IncorrectFieldValueError
+	err = LoadFrom(&fileMapsConfig{}, source)
+	if !errors.As(err, &incorrect) || incorrect.Path != "fileMapsConfig.Limits[small]" || incorrect.Value != "300" {
+		t.Errorf("LoadFrom() error = %v, want the incorrect Limits[small]", err)
+	}
+}
@@ -0,0 +1,166 @@
+package settings
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Flags — a source backed by the command-line flags that are generated from the env-tagged
+// fields of a settings struct. The PORT variable becomes the --port flag, DB_URL becomes
+// --db-url. Only the flags that were set on the command line are found, so put Flags first
+// in the sources to let them take precedence over the environment variables.
+type Flags struct {
+	FlagSet *flag.FlagSet
+	values  map[string]*flagValue
+	loader  *Loader
+}
+
+// NewFlags registers a flag for every env-tagged field of the settings struct in the flag set.
+// The help text is taken from the usage or desc tag, the default value from the default tag.
+// Call Parse of the flag set before loading the settings.
+func NewFlags(settings any, set *flag.FlagSet) (*Flags, error) {
+	return (&Loader{}).NewFlags(settings, set)
+}
+
+// NewFlags registers the flags as the NewFlags function does for the loader. The flags are
+// named without the Prefix of the loader, e.g. --port, and are found by the prefixed names
+// the loader looks up, e.g. APP_PORT.
+func (loader *Loader) NewFlags(settings any, set *flag.FlagSet) (*Flags, error) {
+	settingsType := reflect.TypeOf(settings)
+	for settingsType != nil && settingsType.Kind() == reflect.Ptr {
+		settingsType = settingsType.Elem()
+	}
+	if settingsType == nil || settingsType.Kind() != reflect.Struct {
+		return nil, ErrNotAStruct
+	}
+
+	flags := &Flags{
+		FlagSet: set,
+		values:  make(map[string]*flagValue),
+		loader:  loader,
+	}
+	flags.register(settingsType, "", "")
+
+	return flags, nil
+}
+
+// register adds the flags for the fields of the struct type and its nested structs. The prefix
+// is the composed prefix tag of the nested struct, derived is the start of the derived env names.
+func (flags *Flags) register(structType reflect.Type, envNamePrefix, derived string) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		envTag, hasEnvTag := field.Tag.Lookup(env)
+		if envTag == omit {
+			continue
+		}
+
+		// the structs converted by the loader, e.g. with a registered parser, get a single flag
+		if flags.loader.isNested(field.Type) {
+			flags.register(derefType(field.Type), envNamePrefix+prefixTag(field), nestedDerived(field, derived))
+			continue
+		}
+
+		fieldType := derefType(field.Type)
+
+		// the slices and maps of structs have no fixed names to generate the flags for
+		if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map) && flags.loader.isNested(fieldType.Elem()) {
+			continue
+		}
+
+		// the env name is derived the same way the loader derives it
+		if !hasEnvTag {
+			if !flags.loader.DeriveNames || !field.IsExported() {
+				continue
+			}
+			envTag = derived + deriveName(field.Name)
+		}
+
+		// the flag is generated for the main env name only
+		names := envNames(envTag)
+		if len(names) == 0 {
+			continue
+		}
+		envTag = envNamePrefix + names[0]
+		key := flags.loader.Prefix + envTag
+		if flags.values[key] != nil {
+			continue
+		}
+
+		value := &flagValue{isBool: fieldType.Kind() == reflect.Bool}
+		flags.values[key] = value
+
+		help, ok := field.Tag.Lookup(usage)
+		if !ok {
+			help = field.Tag.Get(desc)
+		}
+
+		name := flagName(envTag)
+		flags.FlagSet.Var(value, name, help)
+		flags.FlagSet.Lookup(name).DefValue = field.Tag.Get(defaultSetting)
+	}
+}
+
+// derefType returns the type the pointer type points to, or the type itself.
+func derefType(valueType reflect.Type) reflect.Type {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	return valueType
+}
+
+// Lookup returns the value of the flag generated for the env variable name if the flag was set.
+// The name includes the Prefix of the loader the flags were created by.
+func (flags *Flags) Lookup(key string) (string, bool) {
+	value, ok := flags.values[key]
+	if !ok || !value.set {
+		return "", false
+	}
+
+	return value.value, true
+}
+
+// Keys returns the env variable names of the flags that were set.
+func (flags *Flags) Keys() []string {
+	var keys []string
+	for key, value := range flags.values {
+		if value.set {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// String returns the layer name of the source.
+func (flags *Flags) String() string {
+	return "flags"
+}
+
+// flagName forms the flag name from the env variable name: DB_URL becomes db-url.
+func flagName(envName string) string {
+	return strings.ReplaceAll(strings.ToLower(envName), "_", "-")
+}
+
+// flagValue — a flag.Value that keeps the raw flag value to be converted by Load.
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (value *flagValue) String() string {
+	return value.value
+}
+
+func (value *flagValue) Set(raw string) error {
+	value.value = raw
+	value.set = true
+	return nil
+}
+
+func (value *flagValue) IsBoolFlag() bool {
+	return value.isBool
+}
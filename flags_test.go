@@ -0,0 +1,265 @@
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flagsConfig struct {
+	Port    uint16        `default:"80"     env:"PORT"     usage:"the port to listen on"`
+	DBURL   string        `desc:"database"  env:"DB_URL"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Secret  string        `env:"-"`
+	Nested  *InternalStruct
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want flagsConfig
+	}{
+		{
+			name: "flags override env",
+			args: []string{"--port", "9090", "--debug", "-db-url=postgres://flag"},
+			env:  map[string]string{"PORT": "8080", "DB_URL": "postgres://env", "TIMEOUT": "1s"},
+			want: flagsConfig{Port: 9090, DBURL: "postgres://flag", Debug: true, Timeout: time.Second},
+		},
+		{
+			name: "env when flags are absent",
+			args: nil,
+			env:  map[string]string{"PORT": "8080", "CACHE": "5"},
+			want: flagsConfig{Port: 8080, Nested: &InternalStruct{CacheSize: "5"}},
+		},
+		{
+			name: "default tag",
+			args: []string{"--cache", "7", "--debug=false"},
+			env:  map[string]string{"DEBUG": "true"},
+			want: flagsConfig{Port: 80, Nested: &InternalStruct{CacheSize: "7"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got flagsConfig
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags, err := NewFlags(&got, set)
+			if err != nil {
+				t.Fatalf("NewFlags() unexpected error = %v", err)
+			}
+
+			if err = set.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error = %v", err)
+			}
+
+			if err = LoadFrom(&got, flags, MapSource(tt.env)); err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			if got.Port != tt.want.Port || got.DBURL != tt.want.DBURL || got.Debug != tt.want.Debug ||
+				got.Timeout != tt.want.Timeout {
+				t.Errorf("LoadFrom() got = %+v, want %+v", got, tt.want)
+			}
+
+			if tt.want.Nested != nil && (got.Nested == nil || *got.Nested != *tt.want.Nested) {
+				t.Errorf("LoadFrom() got nested = %+v, want %+v", got.Nested, tt.want.Nested)
+			}
+		})
+	}
+}
+
+func TestFlagsUsage(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	if _, err := NewFlags(&flagsConfig{}, set); err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	var output bytes.Buffer
+	set.SetOutput(&output)
+	set.PrintDefaults()
+
+	for _, want := range []string{"-port", "the port to listen on (default 80)", "-db-url", "database", "-cache"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("PrintDefaults() output has no %q:\n%s", want, output.String())
+		}
+	}
+
+	if set.Lookup("secret") != nil || set.Lookup("-") != nil {
+		t.Error("NewFlags() must not register the omitted fields")
+	}
+}
+
+func TestNewFlagsNotAStruct(t *testing.T) {
+	if _, err := NewFlags(new(NotAStruct), flag.NewFlagSet("test", flag.ContinueOnError)); !errors.Is(err, ErrNotAStruct) {
+		t.Errorf("NewFlags() error = %v, want %v", err, ErrNotAStruct)
+	}
+}
+
+func TestFlagsPrefixes(t *testing.T) {
+	var got prefixedConfig
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := NewFlags(&got, set)
+	if err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	for _, name := range []string{"primary-port", "replica-pool-size", "port"} {
+		if set.Lookup(name) == nil {
+			t.Errorf("NewFlags() did not register --%s", name)
+		}
+	}
+
+	if set.Lookup("shard") != nil {
+		t.Error("NewFlags() must not register the flags for the slices of structs")
+	}
+
+	err = set.Parse([]string{"--primary-port", "5432", "--replica-port", "5433", "--replica-pool-size", "3", "--port", "1"})
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	if err = LoadFrom(&got, flags); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Primary.Port != 5432 || got.Replica.Port != 5433 || got.Replica.Pool.Size != 3 || got.Unprefix.Port != 1 {
+		t.Errorf("LoadFrom() got = %+v", got)
+	}
+}
+
+func TestFlagsFallbackNames(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := NewFlags(&renamedConfig{}, set)
+	if err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	if set.Lookup("db-url") == nil || set.Lookup("database-url") != nil || set.Lookup("app-port") == nil {
+		t.Error("NewFlags() must register the flags for the main names only")
+	}
+
+	if err = set.Parse([]string{"--db-url", "flag", "--app-port", "80"}); err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	var got renamedConfig
+	if err = LoadFrom(&got, flags); err != nil || got.URL != "flag" {
+		t.Errorf("LoadFrom() got = %+v, error = %v", got, err)
+	}
+}
+
+func TestLoaderFlagsWithPrefix(t *testing.T) {
+	loader := Loader{Prefix: "APP_"}
+
+	var got prefixedConfig
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := loader.NewFlags(&got, set)
+	if err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	err = set.Parse([]string{"--port", "9", "--primary-port", "5432", "--replica-port", "5433"})
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	loader.Sources = []Source{flags, MapSource{"APP_PORT": "1", "APP_PRIMARY_HOST": "env.local"}}
+	if err = loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if got.Unprefix.Port != 9 || got.Primary.Port != 5432 || got.Replica.Port != 5433 || got.Primary.Host != "env.local" {
+		t.Errorf("Load() got = %+v", got)
+	}
+
+	if origin := loader.Provenance()["prefixedConfig.Unprefix.Port"]; origin.Key != "APP_PORT" || origin.Layer != "flags" {
+		t.Errorf("Provenance() Unprefix.Port = %+v, want APP_PORT from the flags", origin)
+	}
+
+	if !slices.Contains(flags.Keys(), "APP_PRIMARY_PORT") {
+		t.Errorf("Keys() = %v, want the prefixed names", flags.Keys())
+	}
+}
+
+func TestLoaderFlagsWithParsers(t *testing.T) {
+	var loader Loader
+	RegisterParser(&loader, parsePoint)
+
+	var got struct {
+		Origin point  `env:"ORIGIN"`
+		Target *point `env:"TARGET"`
+	}
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := loader.NewFlags(&got, set)
+	if err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	for _, name := range []string{"origin", "target", "x", "y"} {
+		if found := set.Lookup(name) != nil; found != (name == "origin" || name == "target") {
+			t.Errorf("Lookup(%q) found = %v", name, found)
+		}
+	}
+
+	if err = set.Parse([]string{"--origin", "1,2", "--target", "3,4"}); err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	loader.Sources = []Source{flags}
+	if err = loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if got.Origin != (point{X: 1, Y: 2}) || got.Target == nil || *got.Target != (point{X: 3, Y: 4}) {
+		t.Errorf("Load() got = %+v", got)
+	}
+}
+
+func TestLoaderFlagsWithDerivedNames(t *testing.T) {
+	loader := Loader{Prefix: "APP_", DeriveNames: true}
+
+	var got derivedConfig
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := loader.NewFlags(&got, set)
+	if err != nil {
+		t.Fatalf("NewFlags() unexpected error = %v", err)
+	}
+
+	var names []string
+	set.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+
+	want := []string{
+		"database-url", "db-max-conns", "db-timeout", "http-port", "log-level",
+		"replica-database-url", "replica-max-conns", "replica-timeout",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("NewFlags() flags = %v, want %v", names, want)
+	}
+
+	err = set.Parse([]string{"--http-port", "8080", "--db-max-conns", "10", "--replica-max-conns", "2", "--log-level", "debug"})
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	loader.Sources = []Source{flags}
+	if err = loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if got.HTTPPort != 8080 || got.DB.MaxConns != 10 || got.Replica == nil || got.Replica.MaxConns != 2 || got.LogLevel != "debug" {
+		t.Errorf("Load() got = %+v", got)
+	}
+
+	if !slices.Contains(flags.Keys(), "APP_DB_MAX_CONNS") {
+		t.Errorf("Keys() = %v, want the prefixed derived names", flags.Keys())
+	}
+}
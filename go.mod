@@ -2,7 +2,11 @@ module github.com/kaatinga/settings
 
 go 1.24.0
 
-require github.com/go-playground/validator/v10 v10.30.1
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/go-playground/validator/v10 v10.30.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/gabriel-vasile/mimetype v1.4.12 // indirect
@@ -0,0 +1,6 @@
+// Package net declares a type that is printed as net.IP like the standard one, it is used
+// to test that the built-in types are not detected by their names.
+package net
+
+// IP — a user type named as the standard net.IP.
+type IP []byte
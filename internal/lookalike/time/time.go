@@ -0,0 +1,6 @@
+// Package time declares a type that is printed as time.Duration like the standard one, it is
+// used to test that the built-in types are not detected by their names.
+package time
+
+// Duration — a user type named as the standard time.Duration.
+type Duration int64
@@ -0,0 +1,8 @@
+// Package url declares a type that is printed as url.URL like the standard one, it is used
+// to test that the built-in types are not detected by their names.
+package url
+
+// URL — a user type named as the standard url.URL.
+type URL struct {
+	Host string `env:"HOST"`
+}
@@ -0,0 +1,167 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Loader — a settings loader with a layered chain of sources. The sources are listed in
+// the order of precedence: the first source that has a value for a key wins, and the
+// default tag is used only when no source has the key. A typical chain is
+//
+//	loader := Loader{Sources: []Source{flags, Environment, file}}
+//
+// that lets the command-line flags override the environment variables, and the
+// environment variables override the config file.
+type Loader struct {
+	// Sources — the sources in the order of precedence. If empty, Environment is used.
+	Sources []Source
+	// CollectErrors — if true, Load walks the whole struct tree and returns all the field
+	// errors followed by the validation errors as Errors instead of failing on the first one.
+	CollectErrors bool
+	// LegacyBool — if true, a bool field is set to true only by "true" in any case and
+	// any other value silently gives false.
+	LegacyBool bool
+	// Prefix — the prefix of all the env names, e.g. "APP_" makes the PORT field read APP_PORT.
+	// It is composed with the prefix tags of the nested structs.
+	Prefix string
+	// DeriveNames — if true, the exported fields without the env tag are looked up by the name
+	// derived from the field path: DB.MaxConns reads DB_MAX_CONNS. The env tags still win.
+	DeriveNames bool
+	// OnDeprecated — receives the values found under the deprecated env names. If nil, they
+	// are logged as warnings with the default slog logger.
+	OnDeprecated func(Deprecation)
+
+	provenance Provenance
+	errors     Errors
+	parsers    map[reflect.Type]parser
+}
+
+// parser — a registered parse function that returns the value of the registered type.
+type parser func(raw string) (reflect.Value, error)
+
+// RegisterParser registers the parse function for the fields of type T in the loader. The
+// registered parsers are consulted before Decoder, encoding.TextUnmarshaler and the built-in
+// conversion, and are used for the elements of lists and maps too. The registration is
+// scoped to the loader, so the loaders do not interfere.
+func RegisterParser[T any](loader *Loader, parse func(string) (T, error)) {
+	if loader.parsers == nil {
+		loader.parsers = make(map[reflect.Type]parser)
+	}
+
+	loader.parsers[reflect.TypeFor[T]()] = func(raw string) (reflect.Value, error) {
+		value, err := parse(raw)
+		return reflect.ValueOf(&value).Elem(), err
+	}
+}
+
+// Deprecation — a value that was found under a deprecated env name. The fallback names of
+// the env tag are deprecated by the deprecated:"true" tag:
+//
+//	URL string `env:"DB_URL,DATABASE_URL" deprecated:"true"`
+type Deprecation struct {
+	// Path — the Go path of the field, e.g. Settings.DB.URL.
+	Path string
+	// Name — the deprecated env name that supplied the value.
+	Name string
+	// Replacement — the env name to use instead.
+	Replacement string
+}
+
+// Origin — the layer that supplied the final value of a field.
+type Origin struct {
+	// Layer — the name of the source, or "default" if the value came from the default tag.
+	Layer string
+	// Key — the key the value was looked up with.
+	Key string
+	// Source — the source that supplied the value, nil for the default tag.
+	Source Source
+}
+
+// Provenance — the origins of the field values keyed by the Go field path, e.g. Settings.DB.Port.
+type Provenance map[string]Origin
+
+// Load loads settings to a struct and records the origin of every field value.
+func (loader *Loader) Load(settings any) error {
+	loader.provenance = make(Provenance)
+	loader.errors = nil
+
+	engine := newEngine(settings, loader)
+	if err := engine.load(); err != nil {
+		return err
+	}
+
+	if err := loader.unreported(engine.Validate.Struct(engine.Value.Interface()), engine.path); err != nil {
+		if !loader.CollectErrors {
+			return err
+		}
+		loader.errors = append(loader.errors, err)
+	}
+
+	if err := runCustomValidation(engine); err != nil {
+		if !loader.CollectErrors {
+			return err
+		}
+		loader.errors = append(loader.errors, err)
+	}
+
+	if len(loader.errors) != 0 {
+		return loader.errors
+	}
+
+	return nil
+}
+
+// unreported removes the validator errors of the fields that already have a field error, e.g.
+// a missing required field, so every problem is reported once. The path is the Go path of
+// the validated struct.
+func (loader *Loader) unreported(err error, path string) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	reported := make(map[string]bool, len(loader.errors))
+	for _, loaded := range loader.errors {
+		var field *fieldError
+		if errors.As(loaded, &field) {
+			reported[field.Path] = true
+		}
+	}
+
+	var rest validator.ValidationErrors
+	for _, fieldErr := range validationErrors {
+		// the namespace starts with the struct type name, e.g. Settings.DB.Port
+		fieldPath := fieldErr.Namespace()
+		if _, tail, ok := strings.Cut(fieldPath, "."); ok {
+			fieldPath = path + "." + tail
+		}
+		if !reported[fieldPath] {
+			rest = append(rest, fieldErr)
+		}
+	}
+
+	if len(rest) == 0 {
+		return nil
+	}
+
+	return rest
+}
+
+// Provenance returns the origins of the field values set during the last Load.
+// The fields that were left untouched are absent.
+func (loader *Loader) Provenance() Provenance {
+	return loader.provenance
+}
+
+// sources returns the sources to look the keys up in.
+func (loader *Loader) sources() []Source {
+	if len(loader.Sources) == 0 {
+		return []Source{Environment}
+	}
+
+	return loader.Sources
+}
@@ -0,0 +1,441 @@
+package settings
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type layeredConfig struct {
+	DBURL    string `default:"127.0.0.1" env:"DB_URL"`
+	Port     uint16 `default:"80"        env:"PORT"`
+	LogLevel string `env:"LOG_LEVEL"`
+	Internal InternalStruct
+	Untagged string
+}
+
+func TestLoaderProvenance(t *testing.T) {
+	flags := Named("flags", MapSource{"PORT": "9090"})
+	file := Named("file", MapSource{"PORT": "8080", "LOG_LEVEL": "debug", "CACHE": "50"})
+
+	loader := Loader{Sources: []Source{flags, MapSource{"LOG_LEVEL": "info"}, file}}
+
+	var got layeredConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if got.Port != 9090 || got.LogLevel != "info" || got.Internal.CacheSize != "50" || got.DBURL != "127.0.0.1" {
+		t.Errorf("Load() got = %+v", got)
+	}
+
+	tests := []struct {
+		path  string
+		layer string
+		key   string
+	}{
+		{path: "layeredConfig.DBURL", layer: "default", key: "DB_URL"},
+		{path: "layeredConfig.Port", layer: "flags", key: "PORT"},
+		{path: "layeredConfig.LogLevel", layer: "map", key: "LOG_LEVEL"},
+		{path: "layeredConfig.Internal.CacheSize", layer: "file", key: "CACHE"},
+	}
+
+	provenance := loader.Provenance()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			origin, ok := provenance[tt.path]
+			if !ok {
+				t.Fatalf("Provenance() has no record for %s", tt.path)
+			}
+
+			if origin.Layer != tt.layer || origin.Key != tt.key {
+				t.Errorf("Provenance()[%s] = %+v, want layer %s and key %s", tt.path, origin, tt.layer, tt.key)
+			}
+		})
+	}
+
+	if _, ok := provenance["layeredConfig.Untagged"]; ok {
+		t.Error("Provenance() must not have a record for the untagged field")
+	}
+
+	if origin := provenance["layeredConfig.DBURL"]; origin.Source != nil {
+		t.Errorf("Provenance() default origin must have no source, got %v", origin.Source)
+	}
+}
+
+func TestLoaderDefaultSource(t *testing.T) {
+	t.Setenv("DB_URL", "from-env")
+
+	var loader Loader
+	var got simpleConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if origin := loader.Provenance()["simpleConfig.DBURL"]; origin.Layer != "env" || origin.Source != Environment {
+		t.Errorf("Provenance() got = %+v, want the env layer", origin)
+	}
+}
+
+type brokenConfig struct {
+	Port     uint8  `env:"PORT"`
+	Required string `env:"REQUIRED" validate:"required"`
+	Nested   *brokenNestedConfig
+	Level    string `env:"LEVEL"    validate:"oneof=debug info"`
+}
+
+type brokenNestedConfig struct {
+	Timeout time.Duration `env:"TIMEOUT"`
+	Channel chan int      `env:"CHANNEL"`
+}
+
+func TestLoaderCollectErrors(t *testing.T) {
+	source := MapSource{"PORT": "300", "TIMEOUT": "soon", "CHANNEL": "1", "LEVEL": "trace"}
+
+	loader := Loader{Sources: []Source{source}, CollectErrors: true}
+	err := loader.Load(&brokenConfig{})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want Errors", err)
+	}
+
+	if len(errs) != 5 {
+		t.Fatalf("Load() returned %d errors, want 5:\n%v", len(errs), err)
+	}
+
+	wantMessages := []string{
+		"brokenConfig.Port (PORT): environment variable 'PORT' has been found but has incorrect value",
+		"brokenConfig.Required (REQUIRED): validation with rule 'required' failed on the field 'Required' of 'string' type",
+		"brokenConfig.Nested.Timeout (TIMEOUT): environment variable 'TIMEOUT' has been found but has incorrect value",
+	}
+	for i, want := range wantMessages {
+		if errs[i].Error() != want {
+			t.Errorf("Load() error %d = %q, want %q", i, errs[i], want)
+		}
+	}
+
+	// the missing Required field is reported once, the validator reports only the Level
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Errorf("Load() error must contain the validator errors, got %v", err)
+	}
+
+	if len(validationErrors) != 1 || validationErrors[0].Namespace() != "brokenConfig.Level" {
+		t.Errorf("Load() validator errors = %v, want only brokenConfig.Level", validationErrors)
+	}
+
+	if count := strings.Count(err.Error(), "'Required'"); count != 1 {
+		t.Errorf("Load() reported the Required field %d times, want once:\n%v", count, err)
+	}
+
+	for _, target := range []error{
+		NewIncorrectFieldValueError("PORT"),
+		NewUnsupportedFieldError("CHANNEL"),
+		NewValidationFailedError("Required", "string", "required"),
+	} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, target)
+		}
+	}
+
+	if !errors.Is(errors.Join(err), NewIncorrectFieldValueError("PORT")) {
+		t.Error("Errors must be compatible with errors.Join")
+	}
+}
+
+func TestLoaderFailsFast(t *testing.T) {
+	loader := Loader{Sources: []Source{MapSource{"PORT": "300", "TIMEOUT": "soon"}}}
+	err := loader.Load(&brokenConfig{})
+
+	var errs Errors
+	if errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want the first error only", err)
+	}
+
+	if !errors.Is(err, NewIncorrectFieldValueError("PORT")) {
+		t.Errorf("Load() error = %v, want %v", err, NewIncorrectFieldValueError("PORT"))
+	}
+}
+
+// point imitates a third-party type that cannot implement the interfaces of the package.
+type point struct {
+	X, Y int
+}
+
+type parserConfig struct {
+	Origin point            `env:"ORIGIN"`
+	Points []point          `env:"POINTS"  sep:";"`
+	Named  map[string]point `env:"NAMED"   sep:";" kvsep:"="`
+	Level  slog.Level       `env:"LEVEL"`
+}
+
+func parsePoint(raw string) (point, error) {
+	x, y, found := strings.Cut(raw, ",")
+	if !found {
+		return point{}, errors.New("a point must be 'x,y'")
+	}
+
+	var p point
+	var err error
+	if p.X, err = strconv.Atoi(x); err != nil {
+		return point{}, err
+	}
+	p.Y, err = strconv.Atoi(y)
+
+	return p, err
+}
+
+func TestRegisterParser(t *testing.T) {
+	loader := Loader{Sources: []Source{MapSource{
+		"ORIGIN": "1,2",
+		"POINTS": "3,4;5,6",
+		"NAMED":  "a=7,8",
+		"LEVEL":  "loud",
+	}}}
+	RegisterParser(&loader, parsePoint)
+	RegisterParser(&loader, func(raw string) (slog.Level, error) {
+		// the registered parser wins over encoding.TextUnmarshaler of slog.Level
+		if raw == "loud" {
+			return slog.LevelError, nil
+		}
+		return slog.LevelInfo, nil
+	})
+
+	var got parserConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	want := parserConfig{
+		Origin: point{1, 2},
+		Points: []point{{3, 4}, {5, 6}},
+		Named:  map[string]point{"a": {7, 8}},
+		Level:  slog.LevelError,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() got = %+v, want %+v", got, want)
+	}
+
+	loader.Sources = []Source{MapSource{"ORIGIN": "1"}}
+	err := loader.Load(&parserConfig{})
+
+	var target *IncorrectFieldValueError
+	if !errors.As(err, &target) || target.Path != "parserConfig.Origin" || target.Type != "settings.point" {
+		t.Errorf("Load() error = %v, want *IncorrectFieldValueError of parserConfig.Origin", err)
+	}
+}
+
+func TestRegisterParserIsScopedToLoader(t *testing.T) {
+	for _, want := range []int{1, 2} {
+		t.Run(strconv.Itoa(want), func(t *testing.T) {
+			t.Parallel()
+
+			loader := Loader{Sources: []Source{MapSource{"ORIGIN": "any"}}}
+			RegisterParser(&loader, func(string) (point, error) {
+				return point{X: want}, nil
+			})
+
+			var got parserConfig
+			if err := loader.Load(&got); err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+
+			if got.Origin.X != want {
+				t.Errorf("Load() got = %+v, want X = %d", got.Origin, want)
+			}
+		})
+	}
+
+	// a loader without the parser processes the type as a nested struct
+	if err := LoadFrom(&parserConfig{}, MapSource{"ORIGIN": "1,2"}); err != nil {
+		t.Errorf("LoadFrom() unexpected error = %v", err)
+	}
+}
+
+func TestDeriveName(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "Port", want: "PORT"},
+		{field: "MaxConns", want: "MAX_CONNS"},
+		{field: "HTTPPort", want: "HTTP_PORT"},
+		{field: "DBURL", want: "DBURL"},
+		{field: "UserID", want: "USER_ID"},
+		{field: "TLSCertFile", want: "TLS_CERT_FILE"},
+		{field: "Retry3Times", want: "RETRY3_TIMES"},
+		{field: "V2API", want: "V2_API"},
+		{field: "already_snake", want: "ALREADY_SNAKE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := deriveName(tt.field); got != tt.want {
+				t.Errorf("deriveName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type derivedDB struct {
+	MaxConns int
+	URL      string        `env:"DATABASE_URL"`
+	Timeout  time.Duration `default:"5s"`
+}
+
+type derivedEmbedded struct {
+	LogLevel string
+}
+
+type derivedConfig struct {
+	derivedEmbedded
+	HTTPPort  uint16
+	DB        derivedDB
+	Replica   *derivedDB `prefix:"REPLICA_"`
+	Upstreams []upstream
+	Skipped   string `env:"-"`
+	internal  string
+}
+
+func TestLoaderDeriveNames(t *testing.T) {
+	source := MapSource{
+		"HTTP_PORT":         "8080",
+		"LOG_LEVEL":         "debug",
+		"DB_MAX_CONNS":      "10",
+		"DATABASE_URL":      "postgres://explicit",
+		"DB_URL":            "postgres://derived",
+		"REPLICA_MAX_CONNS": "2",
+		"UPSTREAMS_0_HOST":  "a.local",
+		"SKIPPED":           "value",
+		"INTERNAL":          "value",
+	}
+
+	loader := Loader{Sources: []Source{source}, DeriveNames: true}
+
+	var got derivedConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	want := derivedConfig{
+		derivedEmbedded: derivedEmbedded{LogLevel: "debug"},
+		HTTPPort:        8080,
+		DB:              derivedDB{MaxConns: 10, URL: "postgres://explicit", Timeout: 5 * time.Second},
+		Replica:         &derivedDB{MaxConns: 2, Timeout: 5 * time.Second},
+		Upstreams:       []upstream{{Host: "a.local", Port: 80}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() got = %+v, want %+v", got, want)
+	}
+
+	if origin := loader.Provenance()["derivedConfig.DB.MaxConns"]; origin.Key != "DB_MAX_CONNS" {
+		t.Errorf("Provenance() DB.MaxConns = %+v, want DB_MAX_CONNS", origin)
+	}
+
+	var notDerived derivedConfig
+	if err := LoadFrom(&notDerived, source); err != nil || notDerived.HTTPPort != 0 || notDerived.DB.MaxConns != 0 {
+		t.Errorf("LoadFrom() got = %+v, error = %v, want the untagged fields untouched", notDerived, err)
+	}
+}
+
+type renamedConfig struct {
+	URL     string        `env:"DB_URL,DATABASE_URL" deprecated:"true"`
+	Timeout time.Duration `env:"DB_TIMEOUT, TIMEOUT, DB_WAIT"`
+	Nested  renamedNested `prefix:"APP_"`
+}
+
+type renamedNested struct {
+	Port uint16 `env:"PORT,LISTEN_PORT" deprecated:"true" validate:"required"`
+}
+
+func TestLoaderFallbackNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		sources      []Source
+		want         renamedConfig
+		deprecations []Deprecation
+	}{
+		{
+			name:    "main names",
+			sources: []Source{MapSource{"DB_URL": "new", "DATABASE_URL": "old", "DB_TIMEOUT": "1s", "TIMEOUT": "2s", "APP_PORT": "80"}},
+			want:    renamedConfig{URL: "new", Timeout: time.Second, Nested: renamedNested{Port: 80}},
+		},
+		{
+			name:    "fallback names",
+			sources: []Source{MapSource{"DATABASE_URL": "old", "DB_WAIT": "3s", "TIMEOUT": "2s", "APP_LISTEN_PORT": "81"}},
+			want:    renamedConfig{URL: "old", Timeout: 2 * time.Second, Nested: renamedNested{Port: 81}},
+			deprecations: []Deprecation{
+				{Path: "renamedConfig.URL", Name: "DATABASE_URL", Replacement: "DB_URL"},
+				{Path: "renamedConfig.Nested.Port", Name: "APP_LISTEN_PORT", Replacement: "APP_PORT"},
+			},
+		},
+		{
+			name:         "source precedence wins over name order",
+			sources:      []Source{MapSource{"DATABASE_URL": "first", "APP_PORT": "80"}, MapSource{"DB_URL": "second"}},
+			want:         renamedConfig{URL: "first", Nested: renamedNested{Port: 80}},
+			deprecations: []Deprecation{{Path: "renamedConfig.URL", Name: "DATABASE_URL", Replacement: "DB_URL"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deprecations []Deprecation
+			loader := Loader{Sources: tt.sources, OnDeprecated: func(deprecation Deprecation) {
+				deprecations = append(deprecations, deprecation)
+			}}
+
+			var got renamedConfig
+			if err := loader.Load(&got); err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Load() got = %+v, want %+v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(deprecations, tt.deprecations) {
+				t.Errorf("OnDeprecated() got = %+v, want %+v", deprecations, tt.deprecations)
+			}
+		})
+	}
+
+	loader := Loader{Sources: []Source{MapSource{"TIMEOUT": "2s", "APP_PORT": "80"}}}
+	if err := loader.Load(&renamedConfig{}); err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if origin := loader.Provenance()["renamedConfig.Timeout"]; origin.Key != "TIMEOUT" {
+		t.Errorf("Provenance() Timeout = %+v, want the TIMEOUT key", origin)
+	}
+
+	err := LoadFrom(&renamedConfig{}, MapSource{})
+
+	var validation *ValidationFailedError
+	if !errors.As(err, &validation) || validation.Env != "APP_PORT" {
+		t.Errorf("LoadFrom() error = %v, want the required APP_PORT", err)
+	}
+}
+
+func TestLoaderDeprecationLog(t *testing.T) {
+	var buffer strings.Builder
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	if err := LoadFrom(&renamedConfig{}, MapSource{"DATABASE_URL": "old", "APP_PORT": "80"}); err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	for _, want := range []string{"level=WARN", "name=DATABASE_URL", "replacement=DB_URL", "path=renamedConfig.URL"} {
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("the log %q does not contain %q", buffer.String(), want)
+		}
+	}
+}
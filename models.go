@@ -1,10 +1,15 @@
 package settings
 
 import (
+	"errors"
+	"log/slog"
+	"maps"
 	"math"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -16,17 +21,234 @@ type Engine struct {
 	Value          reflect.Value
 	Field          Loop
 	NumberOfFields int
+	loader         *Loader
+	path           string
+	prefix         string
+	derived        string
+	keys           *[]string
 }
 
 // newEngine creates new model to process settings.
-func newEngine(settings any) *Engine {
+func newEngine(settings any, loader *Loader) *Engine {
 	return &Engine{
 		Value:    reflect.ValueOf(settings),
 		Type:     reflect.TypeOf(settings),
 		Validate: validator.New(),
+		loader:   loader,
+		prefix:   loader.Prefix,
 	}
 }
 
+// nested creates a model to process the nested struct of the current field.
+func (engine *Engine) nested() *Engine {
+	return &Engine{
+		Value:    engine.Field.value,
+		Type:     engine.Field.value.Type(),
+		Validate: engine.Validate,
+		loader:   engine.loader,
+		path:     engine.fieldPath(),
+		prefix:   engine.prefix + engine.Field.prefix,
+		derived:  nestedDerived(engine.Field.field, engine.derived),
+		keys:     engine.keys,
+	}
+}
+
+// nestedDerived returns the start of the derived env names in the nested struct of the field,
+// derived is the start in the parent struct: the names in DB start with DB_. The prefix tag and
+// the embedding replace the field name.
+func nestedDerived(field reflect.StructField, derived string) string {
+	if prefixTag(field) != "" {
+		return ""
+	}
+	if field.Anonymous {
+		return derived
+	}
+
+	return derived + deriveName(field.Name) + keySeparator
+}
+
+// element creates a model to process a struct element of the current slice or map field.
+// The env names of the element start with the prefix, e.g. UPSTREAM_0_, the path names
+// the element in the errors. If keys is set, the model only collects the env names of
+// the element instead of loading it.
+func (engine *Engine) element(value reflect.Value, path, prefix string, keys *[]string) *Engine {
+	return &Engine{
+		Value:    value,
+		Type:     value.Type(),
+		Validate: engine.Validate,
+		loader:   engine.loader,
+		path:     path,
+		prefix:   prefix,
+		keys:     keys,
+	}
+}
+
+// fieldPath returns the Go path of the current field, e.g. Settings.DB.Port.
+func (engine *Engine) fieldPath() string {
+	return engine.path + "." + engine.Field.field.Name
+}
+
+// lookup searches the sources for the keys, the first found value wins. The sources keep
+// their precedence, the keys are tried in order within every source.
+func (engine *Engine) lookup(keys []string) (value, key string, found bool) {
+	if engine.probing() {
+		*engine.keys = append(*engine.keys, keys...)
+		return "", "", false
+	}
+
+	for _, source := range engine.loader.sources() {
+		for _, key = range keys {
+			if elements, ok := engine.lookupList(source, key); ok {
+				engine.Field.elements = elements
+				engine.record(Origin{Layer: sourceName(source), Key: key, Source: source})
+				return strings.Join(elements, engine.Field.separator), key, true
+			}
+
+			if mapping, ok := engine.lookupMapping(source, key); ok {
+				engine.Field.mapping = mapping
+				engine.record(Origin{Layer: sourceName(source), Key: key, Source: source})
+				return engine.Field.joinMapping(), key, true
+			}
+
+			if value, found = source.Lookup(key); found {
+				engine.record(Origin{Layer: sourceName(source), Key: key, Source: source})
+				return value, key, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// warnDeprecated reports that the value of the current field was found under the deprecated name.
+func (engine *Engine) warnDeprecated(name string) {
+	deprecation := Deprecation{Path: engine.fieldPath(), Name: name, Replacement: engine.Field.envTag}
+	if engine.loader.OnDeprecated != nil {
+		engine.loader.OnDeprecated(deprecation)
+		return
+	}
+
+	slog.Warn("deprecated environment variable",
+		"name", deprecation.Name, "replacement", deprecation.Replacement, "path", deprecation.Path)
+}
+
+// lookupList returns the elements of the list stored under the key if the current field
+// is a list and the source keeps the list elements.
+func (engine *Engine) lookupList(source Source, key string) ([]string, bool) {
+	lists, ok := source.(ListSource)
+	if !ok {
+		return nil, false
+	}
+
+	fieldType, ok := engine.collectionType()
+	if !ok || fieldType.Kind() != reflect.Slice || fieldType.Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	return lists.LookupList(key)
+}
+
+// lookupMapping returns the object stored under the key if the current field is a map and
+// the source keeps the objects.
+func (engine *Engine) lookupMapping(source Source, key string) (map[string]string, bool) {
+	mappings, ok := source.(MappingSource)
+	if !ok {
+		return nil, false
+	}
+
+	fieldType, ok := engine.collectionType()
+	if !ok || fieldType.Kind() != reflect.Map {
+		return nil, false
+	}
+
+	return mappings.LookupMapping(key)
+}
+
+// collectionType returns the type of the current field without the pointers, ok is false if
+// the field is converted from a single string.
+func (engine *Engine) collectionType() (reflect.Type, bool) {
+	fieldType := engine.Field.value.Type()
+	for fieldType.Kind() == reflect.Ptr && !engine.loader.isConvertible(fieldType) {
+		fieldType = fieldType.Elem()
+	}
+
+	return fieldType, !engine.loader.isConvertible(fieldType)
+}
+
+// acceptsByteSize returns true if the current field, or its list or map elements, is an integer
+// the byte sizes are converted to.
+func (engine *Engine) acceptsByteSize() bool {
+	valueType, ok := engine.collectionType()
+	if !ok {
+		return false
+	}
+
+	switch valueType.Kind() { //nolint:exhaustive
+	case reflect.Slice:
+		// []byte is loaded as the raw bytes
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		valueType = valueType.Elem()
+	case reflect.Map:
+		valueType = valueType.Elem()
+	}
+
+	for valueType.Kind() == reflect.Ptr && !engine.loader.isConvertible(valueType) {
+		valueType = valueType.Elem()
+	}
+	if engine.loader.isConvertible(valueType) || valueType == durationType {
+		return false
+	}
+
+	switch valueType.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// exists returns true if any of the keys is found in the sources.
+func (engine *Engine) exists(keys []string) bool {
+	for _, key := range keys {
+		for _, source := range engine.loader.sources() {
+			if _, ok := source.Lookup(key); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fail returns the error of the current field, or saves it and returns nil if the
+// loader collects the errors.
+func (engine *Engine) fail(err error) error {
+	if !engine.loader.CollectErrors || engine.probing() {
+		return err
+	}
+
+	engine.loader.errors = append(engine.loader.errors, &fieldError{
+		Path: engine.fieldPath(),
+		Env:  engine.Field.envTag,
+		Err:  err,
+	})
+
+	return nil
+}
+
+// probing returns true if the model only collects the env names of the struct.
+func (engine *Engine) probing() bool {
+	return engine.keys != nil
+}
+
+// record saves the origin of the current field value.
+func (engine *Engine) record(origin Origin) {
+	engine.loader.provenance[engine.fieldPath()] = origin
+}
+
 // Loop — variables that used during field processing.
 type Loop struct {
 	value             reflect.Value
@@ -35,32 +257,40 @@ type Loop struct {
 	validationRule    string
 	defaultSetting    string
 	field             reflect.StructField
-	durationValue     time.Duration
-	int64Value        int64
-	uint64Value       uint64
-	float64Value      float64
 	hasEnvTag         bool
 	mustBeOmitted     bool
 	hasEnvValue       bool
 	mustBeValidated   bool
 	required          bool
 	hasDefaultSetting bool
+	secret            bool
+	separator         string
+	trim              bool
+	skipEmpty         bool
+	keyValueSeparator string
+	layout            string
+	unit              string
+	base              int
+	prefix            string
+	envNames          []string
+	deprecated        bool
+	tagError          error
+	elements          []string
+	mapping           map[string]string
 }
 
-// exceedsMaximumUint returns true if the value exceeds the maximum uint value.
-func (field *Loop) exceedsMaximumUint() bool {
-	var kind reflect.Kind
-	if kind = field.value.Kind(); kind == reflect.Uint {
+// exceedsMaximumUint returns true if the value exceeds the maximum value of the uint kind.
+func exceedsMaximumUint(kind reflect.Kind, value uint64) bool {
+	if kind == reflect.Uint {
 		kind = reflect.Uint64
 	}
 
 	//nolint:gomnd // it is a formula
-	return field.uint64Value > 1<<(2<<(uint64(kind)-6))-1
+	return value > 1<<(2<<(uint64(kind)-6))-1
 }
 
-// notInIntRange returns true if the value is not in the int range.
-func (field *Loop) notInIntRange() bool {
-	kind := field.value.Kind()
+// notInIntRange returns true if the value is not in the range of the int kind.
+func notInIntRange(kind reflect.Kind, value int64) bool {
 	var minimum, maximum int64
 
 	switch kind {
@@ -76,7 +306,75 @@ func (field *Loop) notInIntRange() bool {
 		return false
 	}
 
-	return field.int64Value > maximum || field.int64Value < minimum
+	return value > maximum || value < minimum
+}
+
+// split splits the list value of the field to the elements. The elements of a list found in
+// a ListSource are taken as they are.
+func (field *Loop) split(raw string) []string {
+	var elements []string
+	if field.elements != nil {
+		elements = append(elements, field.elements...)
+	} else if field.separator != "" && strings.TrimSpace(field.separator) == "" {
+		// a whitespace separator splits by any run of whitespace
+		elements = strings.Fields(raw)
+	} else {
+		elements = strings.Split(raw, field.separator)
+	}
+
+	if !field.trim && !field.skipEmpty {
+		return elements
+	}
+
+	result := elements[:0]
+	for _, element := range elements {
+		if field.trim {
+			element = strings.TrimSpace(element)
+		}
+		if field.skipEmpty && element == "" {
+			continue
+		}
+		result = append(result, element)
+	}
+
+	return result
+}
+
+// pairs returns the keys and the values of the map value of the field. The pairs of an object
+// found in a MappingSource are taken as they are, otherwise the value is split to the pairs.
+// If a pair has no key-value separator, ok is false and the pair is returned as malformed.
+func (field *Loop) pairs(raw string) (pairs [][2]string, malformed string, ok bool) {
+	if field.mapping != nil {
+		for _, key := range slices.Sorted(maps.Keys(field.mapping)) {
+			pairs = append(pairs, [2]string{key, field.mapping[key]})
+		}
+	} else {
+		for _, pair := range field.split(raw) {
+			key, value, found := strings.Cut(pair, field.keyValueSeparator)
+			if !found {
+				return nil, pair, false
+			}
+			pairs = append(pairs, [2]string{key, value})
+		}
+	}
+
+	if field.trim {
+		for i := range pairs {
+			pairs[i] = [2]string{strings.TrimSpace(pairs[i][0]), strings.TrimSpace(pairs[i][1])}
+		}
+	}
+
+	return pairs, "", true
+}
+
+// joinMapping forms the raw map value of the object found in a MappingSource for the errors.
+func (field *Loop) joinMapping() string {
+	pairs := make([]string, 0, len(field.mapping))
+	for _, key := range slices.Sorted(maps.Keys(field.mapping)) {
+		pairs = append(pairs, key+field.keyValueSeparator+field.mapping[key])
+	}
+
+	return strings.Join(pairs, field.separator)
 }
 
 // getStruct checks and returns a struct to process.
@@ -111,6 +409,11 @@ func (engine *Engine) getStruct() error {
 		return ErrNotAStruct
 	}
 
+	// the root struct path starts with the type name
+	if engine.path == "" {
+		engine.path = engine.Type.Name()
+	}
+
 	// checking the number of the fields in the struct.
 	engine.NumberOfFields = engine.Type.NumField()
 	if engine.NumberOfFields == 0 {
@@ -120,12 +423,64 @@ func (engine *Engine) getStruct() error {
 	return nil
 }
 
-// validationFailedError forms validation error.
+// validationFailed forms validation error.
 func (engine *Engine) validationFailed() error {
-	return &validationFailedError{
+	return &ValidationFailedError{
 		Name:           engine.Field.field.Name,
 		Type:           engine.Field.value.Type().String(),
 		ValidationRule: engine.Field.validationRule,
+		Env:            engine.Field.envTag,
+		Path:           engine.fieldPath(),
+	}
+}
+
+// unsupportedField forms unsupported field type error.
+func (engine *Engine) unsupportedField(path string, valueType reflect.Type) error {
+	return &UnsupportedFieldError{
+		Env:  engine.Field.envTag,
+		Path: path,
+		Type: valueType.String(),
+	}
+}
+
+// incorrectFieldValue forms incorrect value error wrapping the parse error.
+func (engine *Engine) incorrectFieldValue(path, raw string, valueType reflect.Type, err error) error {
+	if engine.Field.secret {
+		raw = redacted
+		err = redactCause(err)
+	}
+
+	return &IncorrectFieldValueError{
+		Env:   engine.Field.envTag,
+		Path:  path,
+		Value: raw,
+		Type:  valueType.String(),
+		Err:   err,
+	}
+}
+
+// redactCause replaces the parse error of a secret value with one that does not contain the
+// value. The number errors keep their cause, so errors.Is(err, strconv.ErrRange) still works.
+func redactCause(err error) error {
+	var numError *strconv.NumError
+	switch {
+	case errors.As(err, &numError):
+		return &strconv.NumError{Func: numError.Func, Num: redacted, Err: redactCause(numError.Err)}
+	case errors.Is(err, strconv.ErrRange):
+		return strconv.ErrRange
+	case errors.Is(err, strconv.ErrSyntax):
+		return strconv.ErrSyntax
+	case errors.Is(err, ErrNoKeyValueSeparator):
+		return ErrNoKeyValueSeparator
+	default:
+		return ErrRedactedCause
+	}
+}
+
+// invalidTag saves the error of the tag value of the current field, the first error wins.
+func (engine *Engine) invalidTag(tag, value string) {
+	if engine.Field.tagError == nil {
+		engine.Field.tagError = &InvalidTagError{Path: engine.fieldPath(), Tag: tag, Value: value}
 	}
 }
 
@@ -157,7 +512,112 @@ func (engine *Engine) startIteration(i int) {
 		return
 	}
 	engine.Field.mustBeOmitted = false
+	engine.Field.tagError = nil
+	engine.Field.elements = nil
+	engine.Field.mapping = nil
+
+	// the env name is derived from the field path if the loader is asked to
+	if !engine.Field.hasEnvTag && engine.loader.DeriveNames && engine.Field.field.IsExported() {
+		engine.Field.envTag = engine.derived + deriveName(engine.Field.field.Name)
+		engine.Field.hasEnvTag = true
+	}
+
+	// the first env name is the main one, the rest are the fallbacks
+	engine.Field.envNames = engine.Field.envNames[:0]
+	if engine.Field.hasEnvTag {
+		for _, name := range envNames(engine.Field.envTag) {
+			engine.Field.envNames = append(engine.Field.envNames, engine.prefix+name)
+		}
+		if len(engine.Field.envNames) != 0 {
+			engine.Field.envTag = engine.Field.envNames[0]
+		}
+	}
+	engine.Field.deprecated = engine.Field.field.Tag.Get(deprecated) == "true"
 
 	// receiving default setting
 	engine.Field.defaultSetting, engine.Field.hasDefaultSetting = engine.Field.field.Tag.Lookup(defaultSetting)
+
+	// the secret values are redacted in errors
+	engine.Field.secret = engine.Field.field.Tag.Get(secret) == "true"
+
+	// receiving the rules to split the lists
+	engine.Field.separator = defaultSeparator
+	for _, name := range []string{sep, separator} {
+		if tag, ok := engine.Field.field.Tag.Lookup(name); ok {
+			// an empty separator would split the value to single characters
+			if tag == "" {
+				engine.invalidTag(name, tag)
+				break
+			}
+			engine.Field.separator = tag
+			break
+		}
+	}
+	engine.Field.trim = engine.Field.field.Tag.Get(trim) == "true"
+	engine.Field.skipEmpty = engine.Field.field.Tag.Get(skipEmpty) == "true"
+	engine.Field.layout = engine.Field.field.Tag.Get(layout)
+	engine.Field.unit = engine.Field.field.Tag.Get(unit)
+	if engine.Field.unit != "" && (engine.Field.unit != bytesUnit || !engine.acceptsByteSize()) {
+		engine.invalidTag(unit, engine.Field.unit)
+	}
+	engine.Field.base = defaultBase
+	if tag, ok := engine.Field.field.Tag.Lookup(base); ok {
+		var err error
+		engine.Field.base, err = strconv.Atoi(tag)
+		if err != nil || engine.Field.base != 0 && (engine.Field.base < 2 || engine.Field.base > 36) {
+			engine.invalidTag(base, tag)
+		}
+	}
+	engine.Field.keyValueSeparator = defaultKeyValueSeparator
+	if tag, ok := engine.Field.field.Tag.Lookup(kvsep); ok {
+		if tag == "" {
+			engine.invalidTag(kvsep, tag)
+		} else {
+			engine.Field.keyValueSeparator = tag
+		}
+	}
+
+	// receiving the env name prefix of a nested struct
+	engine.Field.prefix = prefixTag(engine.Field.field)
+}
+
+// prefixTag returns the env name prefix of the nested struct field from the prefix or envPrefix tag.
+func prefixTag(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup(prefix); ok {
+		return tag
+	}
+
+	return field.Tag.Get(envPrefix)
+}
+
+// deriveName forms the env name from the Go field name: MaxConns becomes MAX_CONNS, the
+// acronyms are kept together, so HTTPPort becomes HTTP_PORT.
+func deriveName(fieldName string) string {
+	runes := []rune(fieldName)
+
+	var name strings.Builder
+	for i, char := range runes {
+		if i > 0 && unicode.IsUpper(char) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || unicode.IsUpper(previous) && nextIsLower {
+				name.WriteString(keySeparator)
+			}
+		}
+		name.WriteRune(unicode.ToUpper(char))
+	}
+
+	return name.String()
+}
+
+// envNames splits the env tag into the env names, e.g. "DB_URL,DATABASE_URL".
+func envNames(tag string) []string {
+	var names []string
+	for _, name := range strings.Split(tag, envNameSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
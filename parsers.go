@@ -0,0 +1,285 @@
+package settings
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// parseBool parses the spellings accepted by strconv.ParseBool plus yes/no, on/off and y/n
+// in any case.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	default:
+		return false, &strconv.NumError{Func: "ParseBool", Num: value, Err: strconv.ErrSyntax}
+	}
+}
+
+// parseIP parses an IPv4 or IPv6 address, unlike net.IP.UnmarshalText an empty value is an error.
+func parseIP(value string) (net.IP, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: value}
+	}
+
+	return ip, nil
+}
+
+// parseFileMode parses the file mode in octal with or without the 0o prefix, e.g. 0755 or 0o644.
+func parseFileMode(value string) (os.FileMode, error) {
+	digits := value
+	if len(digits) > 2 && digits[0] == '0' && (digits[1] == 'o' || digits[1] == 'O') {
+		digits = digits[2:]
+	}
+
+	mode, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseFileMode", Num: value, Err: errors.Unwrap(err)}
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// timeLayouts — the layouts of the time package that can be referred by name in the layout tag.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// parseTime parses the time with the layout or the name of a time package layout.
+// RFC3339 is used if the layout is empty.
+func parseTime(value, layout string) (time.Time, error) {
+	if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	return time.Parse(layout, value)
+}
+
+// parseWeekday parses the weekday by its full or three-letter name in any case, or by
+// its number where Sunday is 0.
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if matchesName(value, day.String()) {
+			return day, nil
+		}
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < int(time.Sunday) || number > int(time.Saturday) {
+		return 0, &strconv.NumError{Func: "ParseWeekday", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	return time.Weekday(number), nil
+}
+
+// parseMonth parses the month by its full or three-letter name in any case, or by its
+// number where January is 1.
+func parseMonth(value string) (time.Month, error) {
+	for month := time.January; month <= time.December; month++ {
+		if matchesName(value, month.String()) {
+			return month, nil
+		}
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < int(time.January) || number > int(time.December) {
+		return 0, &strconv.NumError{Func: "ParseMonth", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	return time.Month(number), nil
+}
+
+// matchesName returns true if the value is the name or its three-letter abbreviation in any case.
+func matchesName(value, name string) bool {
+	return strings.EqualFold(value, name) || len(value) == 3 && strings.EqualFold(value, name[:3])
+}
+
+// the types that have a dedicated built-in conversion, they are compared by identity, so
+// a user type with the same printed name, e.g. another url.URL, is not mistaken for them
+var (
+	durationType         = reflect.TypeFor[time.Duration]()
+	timestampType        = reflect.TypeFor[time.Time]()
+	locationType         = reflect.TypeFor[*time.Location]()
+	weekdayType          = reflect.TypeFor[time.Weekday]()
+	monthType            = reflect.TypeFor[time.Month]()
+	urlValueType         = reflect.TypeFor[url.URL]()
+	urlPointerType       = reflect.TypeFor[*url.URL]()
+	ipAddressType        = reflect.TypeFor[net.IP]()
+	ipNetworkType        = reflect.TypeFor[net.IPNet]()
+	ipNetworkPointerType = reflect.TypeFor[*net.IPNet]()
+	netipAddrType        = reflect.TypeFor[netip.Addr]()
+	netipPrefixType      = reflect.TypeFor[netip.Prefix]()
+	netipAddrPortType    = reflect.TypeFor[netip.AddrPort]()
+	fileModeType         = reflect.TypeFor[os.FileMode]()
+)
+
+// isBuiltinType returns true if the type has a dedicated built-in conversion.
+func isBuiltinType(valueType reflect.Type) bool {
+	switch valueType {
+	case timestampType, locationType, weekdayType, monthType,
+		urlValueType, urlPointerType, ipAddressType, ipNetworkType, ipNetworkPointerType,
+		netipAddrType, netipPrefixType, netipAddrPortType, fileModeType:
+		return true
+	default:
+		return false
+	}
+}
+
+// setBuiltin converts the raw value of a type that has a dedicated conversion and sets it,
+// converted is false for the other types.
+func setBuiltin(value reflect.Value, raw, timeLayout string) (converted bool, err error) {
+	var result any
+	switch value.Type() {
+	case timestampType:
+		result, err = parseTime(raw, timeLayout)
+	case locationType:
+		result, err = time.LoadLocation(raw)
+	case weekdayType:
+		result, err = parseWeekday(raw)
+	case monthType:
+		result, err = parseMonth(raw)
+	case urlPointerType:
+		result, err = url.Parse(raw)
+	case urlValueType:
+		var parsed *url.URL
+		if parsed, err = url.Parse(raw); err == nil {
+			result = *parsed
+		}
+	case ipAddressType:
+		result, err = parseIP(raw)
+	case ipNetworkPointerType:
+		_, result, err = net.ParseCIDR(raw)
+	case ipNetworkType:
+		var parsed *net.IPNet
+		if _, parsed, err = net.ParseCIDR(raw); err == nil {
+			result = *parsed
+		}
+	case netipAddrType:
+		result, err = netip.ParseAddr(raw)
+	case netipPrefixType:
+		result, err = netip.ParsePrefix(raw)
+	case netipAddrPortType:
+		result, err = netip.ParseAddrPort(raw)
+	case fileModeType:
+		result, err = parseFileMode(raw)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	value.Set(reflect.ValueOf(result))
+	return true, nil
+}
+
+// byteUnits — the multipliers of the SI and IEC byte units in lower case.
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"p":   1e15,
+	"pb":  1e15,
+	"e":   1e18,
+	"eb":  1e18,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+	"ei":  1 << 60,
+	"eib": 1 << 60,
+}
+
+// parseByteSize parses a number of bytes with an optional SI or IEC unit in any case,
+// e.g. 512, 512k, 1.5GB or 64MiB. The result must be a whole number of bytes.
+func parseByteSize(value string) (*big.Int, error) {
+	number := strings.TrimRightFunc(value, unicode.IsLetter)
+	multiplier, ok := byteUnits[strings.ToLower(strings.TrimSpace(value[len(number):]))]
+	if !ok {
+		return nil, &strconv.NumError{Func: "ParseByteSize", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	size, ok := new(big.Rat).SetString(strings.TrimSpace(number))
+	if !ok || strings.ContainsFunc(number, isNotDecimal) {
+		return nil, &strconv.NumError{Func: "ParseByteSize", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	size.Mul(size, new(big.Rat).SetInt64(multiplier))
+	if !size.IsInt() {
+		return nil, &strconv.NumError{Func: "ParseByteSize", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	return size.Num(), nil
+}
+
+// isNotDecimal returns true for the characters that cannot be a part of a decimal number.
+func isNotDecimal(char rune) bool {
+	return !unicode.IsDigit(char) && !strings.ContainsRune("+-. ", char)
+}
+
+// parseByteSizeInt parses a byte size that fits int64.
+func parseByteSizeInt(value string) (int64, error) {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, err
+	}
+	if !size.IsInt64() {
+		return 0, rangeError("ParseByteSize", value)
+	}
+
+	return size.Int64(), nil
+}
+
+// parseByteSizeUint parses a byte size that fits uint64.
+func parseByteSizeUint(value string) (uint64, error) {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, err
+	}
+	if !size.IsUint64() {
+		return 0, rangeError("ParseByteSize", value)
+	}
+
+	return size.Uint64(), nil
+}
@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source — a provider of setting values. Lookup returns the value stored under the key
+// and reports whether the key was found.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// Enumerator — a source that lists its keys. The keys of the maps of nested structs are
+// discovered only in the sources that implement it.
+type Enumerator interface {
+	Keys() []string
+}
+
+// ListSource — a source that keeps the elements of the lists, e.g. a config file. The list
+// fields are loaded from the elements as they are instead of splitting the value, so an
+// element may contain the separator.
+type ListSource interface {
+	LookupList(key string) ([]string, bool)
+}
+
+// MappingSource — a source that keeps the objects, e.g. a config file. The map fields are
+// loaded from the keys and the values of the object instead of a list of key-value pairs.
+type MappingSource interface {
+	LookupMapping(key string) (map[string]string, bool)
+}
+
+// SourceFunc — an adapter to use an ordinary function as a Source.
+type SourceFunc func(key string) (string, bool)
+
+// Lookup calls the function itself.
+func (f SourceFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// String returns the layer name of the source.
+func (f SourceFunc) String() string {
+	return "func"
+}
+
+// MapSource — a Source backed by a map, handy in tests.
+type MapSource map[string]string
+
+// Lookup returns the map value stored under the key.
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Keys returns the keys of the map.
+func (m MapSource) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// String returns the layer name of the source.
+func (m MapSource) String() string {
+	return "map"
+}
+
+type environment struct{}
+
+func (environment) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (environment) Keys() []string {
+	variables := os.Environ()
+	keys := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		if key, _, ok := strings.Cut(variable, "="); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (environment) String() string {
+	return "env"
+}
+
+// Environment — the process environment, the default source of Load.
+var Environment Source = environment{}
+
+type namedSource struct {
+	Source
+	name string
+}
+
+func (source namedSource) String() string {
+	return source.name
+}
+
+// Keys lists the keys of the wrapped source if it is an Enumerator.
+func (source namedSource) Keys() []string {
+	if enumerator, ok := source.Source.(Enumerator); ok {
+		return enumerator.Keys()
+	}
+
+	return nil
+}
+
+// LookupList returns the list elements of the wrapped source if it is a ListSource.
+func (source namedSource) LookupList(key string) ([]string, bool) {
+	if lists, ok := source.Source.(ListSource); ok {
+		return lists.LookupList(key)
+	}
+
+	return nil, false
+}
+
+// LookupMapping returns the object of the wrapped source if it is a MappingSource.
+func (source namedSource) LookupMapping(key string) (map[string]string, bool) {
+	if mappings, ok := source.Source.(MappingSource); ok {
+		return mappings.LookupMapping(key)
+	}
+
+	return nil, false
+}
+
+// Named gives the source a layer name that is reported in the Provenance.
+func Named(name string, source Source) Source {
+	return namedSource{Source: source, name: name}
+}
+
+// sourceName returns the layer name of the source.
+func sourceName(source Source) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("%T", source)
+}
@@ -0,0 +1,124 @@
+package settings
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestLoadFrom tests the LoadFrom function with various sources
+func TestLoadFrom(t *testing.T) {
+	t.Setenv("DB_URL", "from-env")
+
+	tests := []struct {
+		name    string
+		sources []Source
+		want    simpleConfig
+		wantErr error
+	}{
+		{
+			name:    "map_source",
+			sources: []Source{MapSource{"DB_URL": "from-map", "DB_TIMEOUT": "1m"}},
+			want:    simpleConfig{DBURL: "from-map", Timeout: time.Minute},
+		},
+		{
+			name:    "defaults_when_source_is_empty",
+			sources: []Source{MapSource{}},
+			want:    simpleConfig{DBURL: "127.0.0.1", Timeout: 5 * time.Second},
+		},
+		{
+			name: "first_source_wins",
+			sources: []Source{
+				MapSource{"DB_URL": "first"},
+				MapSource{"DB_URL": "second", "DB_TIMEOUT": "2s"},
+			},
+			want: simpleConfig{DBURL: "first", Timeout: 2 * time.Second},
+		},
+		{
+			name: "source_func",
+			sources: []Source{SourceFunc(func(key string) (string, bool) {
+				return key + "-value", key == "DB_URL"
+			})},
+			want: simpleConfig{DBURL: "DB_URL-value", Timeout: 5 * time.Second},
+		},
+		{
+			name:    "environment_by_default",
+			sources: nil,
+			want:    simpleConfig{DBURL: "from-env", Timeout: 5 * time.Second},
+		},
+		{
+			name:    "bad_value",
+			sources: []Source{MapSource{"DB_TIMEOUT": "soon"}},
+			wantErr: NewIncorrectFieldValueError("DB_TIMEOUT"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got simpleConfig
+			err := LoadFrom(&got, tt.sources...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadFrom() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadFrom() unexpected error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("LoadFrom() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromNestedStruct(t *testing.T) {
+	var got settingsWithStruct
+	err := LoadFrom(&got, MapSource{"PORT": "8080", "CACHE": "big"})
+	if err != nil {
+		t.Fatalf("LoadFrom() unexpected error = %v", err)
+	}
+
+	if got.Port != "8080" || got.Internal == nil || got.Internal.CacheSize != "big" {
+		t.Errorf("LoadFrom() got = %+v", got)
+	}
+}
+
+func TestEnumerator(t *testing.T) {
+	t.Setenv("ENUMERATOR_TEST_KEY", "a=b")
+
+	tests := []struct {
+		name   string
+		source Source
+		want   string
+		found  bool
+	}{
+		{name: "map", source: MapSource{"KEY": "value"}, want: "KEY", found: true},
+		{name: "named map", source: Named("file", MapSource{"KEY": "value"}), want: "KEY", found: true},
+		{name: "environment", source: Environment, want: "ENUMERATOR_TEST_KEY", found: true},
+		{name: "named func", source: Named("func", SourceFunc(func(string) (string, bool) { return "", false })), want: "KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enumerator, ok := tt.source.(Enumerator)
+			if !ok {
+				t.Fatalf("%T does not implement Enumerator", tt.source)
+			}
+
+			found := slices.Contains(enumerator.Keys(), tt.want)
+			if found != tt.found {
+				t.Errorf("Keys() contains %s = %v, want %v", tt.want, found, tt.found)
+			}
+		})
+	}
+
+	var source Source = SourceFunc(nil)
+	if _, ok := source.(Enumerator); ok {
+		t.Error("SourceFunc must not implement Enumerator")
+	}
+}